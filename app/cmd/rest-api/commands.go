@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/config"
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	jwtmanager "github.com/UdinSemen/moscow-events-backend/internal/jwt-manager"
+	"github.com/UdinSemen/moscow-events-backend/internal/services"
+	storage "github.com/UdinSemen/moscow-events-backend/internal/storage/postgres"
+	redis "github.com/UdinSemen/moscow-events-backend/internal/storage/redis"
+)
+
+var (
+	errUnknownCommand = errors.New("unknown command")
+	errMigrateUsage   = errors.New("usage: migrate up | down [-steps n] | version")
+	errBackfillUsage  = errors.New("usage: backfill refresh-tokens | prices [-all]")
+)
+
+// runCommand runs a maintenance subcommand of the binary instead of the server.
+func runCommand(cfg *config.Config, name string, args []string) error {
+	switch name {
+	case "import":
+		return importCommand(cfg, args)
+	case "cleanup":
+		return cleanupCommand(cfg)
+	case "migrate":
+		return migrateCommand(cfg, args)
+	case "backfill":
+		return backfillCommand(cfg, args)
+	default:
+		return fmt.Errorf("%w: %s", errUnknownCommand, name)
+	}
+}
+
+// importCommand imports a batch of events in the format of POST /moderate/event/import and prints the
+// import report.
+func importCommand(cfg *config.Config, args []string) error {
+	const op = "main.importCommand"
+
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := flags.String("file", "-", "json batch of events, - reads stdin")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	var in io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+		defer func() { _ = f.Close() }()
+		in = f
+	}
+
+	var batch models.EventImport
+	if err := json.NewDecoder(in).Decode(&batch); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	postgresStorage, err := storage.InitPgStorage(cfg)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	redisStorage := redis.NewRedisClient(cfg)
+	defer func() { _ = redisStorage.Close() }()
+
+	report, importErr := services.NewEventService(postgresStorage, redisStorage).ImportEvents(context.Background(), batch)
+
+	out := json.NewEncoder(os.Stdout)
+	out.SetIndent("", "  ")
+	if err := out.Encode(report); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if importErr != nil {
+		return fmt.Errorf("%s:%w", op, importErr)
+	}
+	return nil
+}
+
+// cleanupCommand runs the janitor once and prints what it removed.
+func cleanupCommand(cfg *config.Config) error {
+	const op = "main.cleanupCommand"
+
+	postgresStorage, err := storage.InitPgStorage(cfg)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	janitor := services.NewJanitorService(postgresStorage, cfg.Janitor.Interval, cfg.Janitor.GroupMaxAge)
+	report, err := janitor.CleanupEventGroups(context.Background())
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	out.SetIndent("", "  ")
+	if err := out.Encode(report); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+// migrateCommand applies or reverts the embedded migrations, or prints the schema version.
+func migrateCommand(cfg *config.Config, args []string) error {
+	const op = "main.migrateCommand"
+
+	if len(args) == 0 {
+		return fmt.Errorf("%s:%w", op, errMigrateUsage)
+	}
+
+	postgresStorage, err := storage.InitPgStorage(cfg)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := postgresStorage.MigrateUp(ctx)
+		if err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+		fmt.Printf("applied %d migrations\n", applied)
+	case "down":
+		flags := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		steps := flags.Int("steps", 1, "number of migrations to revert")
+		if err := flags.Parse(args[1:]); err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+		reverted, err := postgresStorage.MigrateDown(ctx, *steps)
+		if err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+		fmt.Printf("reverted %d migrations\n", reverted)
+	case "version":
+		version, err := postgresStorage.MigrationVersion(ctx)
+		if err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+		latest, err := storage.LatestMigration()
+		if err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+		fmt.Printf("version %d, latest %d\n", version, latest)
+	default:
+		return fmt.Errorf("%s:%w", op, errMigrateUsage)
+	}
+	return nil
+}
+
+// backfillCommand converts data written by older versions that a schema migration can't convert on its
+// own. Each target is run once after upgrading and is a no-op when nothing is left to convert.
+func backfillCommand(cfg *config.Config, args []string) error {
+	const op = "main.backfillCommand"
+
+	if len(args) == 0 {
+		return fmt.Errorf("%s:%w", op, errBackfillUsage)
+	}
+
+	postgresStorage, err := storage.InitPgStorage(cfg)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "refresh-tokens":
+		if len(args) != 1 {
+			return fmt.Errorf("%s:%w", op, errBackfillUsage)
+		}
+		tokenManager, err := jwtmanager.NewManager(cfg.Jwt.SecretKey, cfg.Jwt.RefreshHashKey, &cfg.Jwt.AccessTokenTTL)
+		if err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+		converted, err := postgresStorage.HashLegacyRefreshTokens(ctx, tokenManager.HashRefreshToken)
+		if err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+		fmt.Printf("hashed %d legacy refresh tokens\n", converted)
+	case "prices":
+		flags := flag.NewFlagSet("backfill prices", flag.ContinueOnError)
+		all := flags.Bool("all", false, "parse the prices of all events again, not only of unparsed ones")
+		if err := flags.Parse(args[1:]); err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+		parsed, err := postgresStorage.NormalizeEventPrices(ctx, services.ParsePrice, *all)
+		if err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+		fmt.Printf("normalized prices of %d events\n", parsed)
+	default:
+		return fmt.Errorf("%s:%w", op, errBackfillUsage)
+	}
+	return nil
+}
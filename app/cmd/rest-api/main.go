@@ -13,9 +13,12 @@ import (
 	server "github.com/UdinSemen/moscow-events-backend/internal/http-server"
 	"github.com/UdinSemen/moscow-events-backend/internal/http-server/handlers"
 	jwt_manager "github.com/UdinSemen/moscow-events-backend/internal/jwt-manager"
+	"github.com/UdinSemen/moscow-events-backend/internal/metrics"
 	"github.com/UdinSemen/moscow-events-backend/internal/services"
 	storage "github.com/UdinSemen/moscow-events-backend/internal/storage/postgres"
 	redis "github.com/UdinSemen/moscow-events-backend/internal/storage/redis"
+	"github.com/UdinSemen/moscow-events-backend/internal/telegram"
+	"github.com/UdinSemen/moscow-events-backend/internal/tracing"
 	"github.com/UdinSemen/moscow-events-backend/pkg/utils"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -50,7 +53,30 @@ func main() {
 	}
 	zap.ReplaceGlobals(logger)
 
-	tokenManager, err := jwt_manager.NewManager(cfg.Jwt.SecretKey, &cfg.Jwt.AccessTokenTTL)
+	if len(os.Args) > 1 {
+		if err := runCommand(cfg, os.Args[1], os.Args[2:]); err != nil {
+			zap.S().Fatalf(err.Error())
+		}
+		return
+	}
+
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		zap.S().Fatalf(err.Error())
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			zap.S().Errorf("Error with flushing traces %s", err)
+		}
+	}()
+
+	tokenManager, err := jwt_manager.NewManager(cfg.Jwt.SecretKey, cfg.Jwt.RefreshHashKey, &cfg.Jwt.AccessTokenTTL)
 	if err != nil {
 		zap.S().Fatalf(err.Error())
 	}
@@ -59,31 +85,81 @@ func main() {
 	if err := redisStorage.Ping(context.Background()); err != nil {
 		zap.S().Fatalf(err.Error())
 	}
+	if err := redisStorage.EnableKeyspaceNotifications(context.Background()); err != nil {
+		zap.S().Warnf("keyspace notifications are off, sign in waits for published updates only: %s", err)
+	}
 	postgresStorage, err := storage.InitPgStorage(cfg)
-	if err := postgresStorage.Ping(); err != nil {
+	if err := postgresStorage.Ping(context.Background()); err != nil {
 		zap.S().Fatalf(err.Error())
 	}
 
 	if err != nil {
 		zap.S().Fatalf(err.Error())
 	}
+
+	if cfg.Postgres.MigrateOnStart {
+		applied, err := postgresStorage.MigrateUp(context.Background())
+		if err != nil {
+			zap.S().Fatalf(err.Error())
+		}
+		if applied > 0 {
+			zap.S().Infof("applied %d migrations", applied)
+		}
+	}
+
+	// without a token the bot and the Login Widget are off, sign in through them is refused
+	var bot telegram.BotAPI
+	if cfg.Telegram.BotToken == "" {
+		zap.S().Warn("telegram bot token is not set, telegram sign in is disabled")
+	} else {
+		client, err := telegram.NewClient(cfg.Telegram.ApiURL, cfg.Telegram.BotToken)
+		if err != nil {
+			zap.S().Fatalf(err.Error())
+		}
+		if cfg.Telegram.WebhookURL != "" {
+			if err := client.SetWebhook(context.Background(), cfg.Telegram.WebhookURL, cfg.Telegram.WebhookSecret); err != nil {
+				zap.S().Fatalf(err.Error())
+			}
+		}
+		bot = client
+	}
+
 	service := services.NewService(redisStorage,
 		postgresStorage,
 		cfg.Jwt.RefreshTokenTTL,
-		tokenManager)
+		cfg.Jwt.AccessTokenTTL,
+		tokenManager,
+		bot,
+		cfg.Telegram.BotToken,
+		cfg.Telegram.WebhookSecret)
 	handler := handlers.NewHandler(service, tokenManager)
 
+	metrics.RegisterPostgres(postgresStorage.DB())
+	metrics.RegisterRedis(redisStorage.PoolStats)
+
+	janitorCtx, stopJanitor := context.WithCancel(context.Background())
+	defer stopJanitor()
+	go services.NewJanitorService(postgresStorage, cfg.Janitor.Interval, cfg.Janitor.GroupMaxAge).Run(janitorCtx)
+
+	router := handler.InitRoutes()
+	if err := router.SetTrustedProxies(cfg.HttpServer.TrustedProxies); err != nil {
+		zap.S().Fatalf(err.Error())
+	}
+
 	srv := new(server.Server)
 	go func() {
-		if err := srv.Run(cfg, handler.InitRoutes()); err != nil && !errors.Is(http.ErrServerClosed, err) {
+		if err := srv.Run(cfg, router); err != nil && !errors.Is(http.ErrServerClosed, err) {
 			zap.S().Panicf("listen %s\n", err)
 		}
 	}()
 
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	zap.S().Info("Shutdown Server ...")
+	service.Health.SetShuttingDown()
+	time.Sleep(cfg.HttpServer.ShutdownDelay)
+	stopJanitor()
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeOut*time.Second)
 	defer cancel()
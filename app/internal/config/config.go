@@ -14,20 +14,53 @@ type Config struct {
 	Postgres   postgres   `yaml:"postgres"`
 	Redis      redis      `yaml:"redis"`
 	Jwt        jwt        `yaml:"jwt"`
+	Telegram   telegram   `yaml:"telegram"`
+	Janitor    janitor    `yaml:"janitor"`
+	Tracing    tracing    `yaml:"tracing"`
 }
 
 type httpServer struct {
 	Address     string `yaml:"address"`
 	Timeout     string `yaml:"timeout"`
 	IdleTimeout string `yaml:"idle-timeout"`
+	// ShutdownDelay keeps serving with a failing readiness before shutting down, so the orchestrator
+	// stops routing to the instance first
+	ShutdownDelay time.Duration `yaml:"shutdown-delay" env-default:"0s"`
+	// TrustedProxies are the addresses or CIDRs of the reverse proxies whose X-Forwarded-For is believed,
+	// with none the client ip is the peer address
+	TrustedProxies []string `yaml:"trusted-proxies"`
 }
 
 type jwt struct {
 	SecretKey       string        `yaml:"secret-key"`
+	RefreshHashKey  string        `yaml:"refresh-token-hash-key"`
 	AccessTokenTTL  time.Duration `yaml:"access_tokenTTL"`
 	RefreshTokenTTL time.Duration `yaml:"refresh_tokenTTL"`
 }
 
+type telegram struct {
+	BotToken      string `yaml:"bot-token"`
+	ApiURL        string `yaml:"api-url" env-default:"https://api.telegram.org"`
+	WebhookURL    string `yaml:"webhook-url"`
+	WebhookSecret string `yaml:"webhook-secret"`
+}
+
+// janitor removes event groups that stopped being actual more than group-max-age ago, a zero interval
+// turns it off.
+type janitor struct {
+	Interval    time.Duration `yaml:"interval" env-default:"1h"`
+	GroupMaxAge time.Duration `yaml:"group-max-age" env-default:"720h"`
+}
+
+// tracing exports spans to stdout or to an OTLP gRPC collector at endpoint, none exports nothing.
+type tracing struct {
+	Exporter    string  `yaml:"exporter" env-default:"none"`
+	Endpoint    string  `yaml:"endpoint" env-default:"localhost:4317"`
+	Insecure    bool    `yaml:"insecure"`
+	ServiceName string  `yaml:"service-name" env-default:"moscow-events-backend"`
+	SampleRatio float64 `yaml:"sample-ratio" env-default:"1"`
+}
+
 type postgres struct {
 	Host     string `yaml:"host"`
 	Port     string `yaml:"port"`
@@ -35,6 +68,8 @@ type postgres struct {
 	Password string `yaml:"password"`
 	DbName   string `yaml:"db-name"`
 	SslMode  string `yaml:"ssl-mode"`
+	// MigrateOnStart applies the pending migrations before the server starts
+	MigrateOnStart bool `yaml:"migrate-on-start"`
 }
 
 type redis struct {
@@ -0,0 +1,14 @@
+package models
+
+const (
+	AuditRefreshTokenReuse = "refresh_token_reuse"
+)
+
+type AuditEvent struct {
+	UserID      string `db:"user_id"`
+	SessionID   string `db:"session_id"`
+	Event       string `db:"event"`
+	IP          string `db:"ip"`
+	FingerPrint string `db:"finger_print"`
+	Details     string `db:"details"`
+}
@@ -0,0 +1,10 @@
+package models
+
+type Category struct {
+	Slug      string `json:"slug" db:"slug"`
+	Name      string `json:"name" db:"name"`
+	Icon      string `json:"icon" db:"icon"`
+	Color     string `json:"color" db:"color"`
+	SortOrder int    `json:"-" db:"sort_order"`
+	Count     int    `json:"count" db:"count"`
+}
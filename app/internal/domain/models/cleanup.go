@@ -0,0 +1,11 @@
+package models
+
+// GroupsCleanup reports a garbage collection of stale event groups. Events that still have favourites
+// after the migration are preserved with their dates, Groups counts only the groups removed entirely.
+type GroupsCleanup struct {
+	Groups             int `json:"groups"`
+	Events             int `json:"events"`
+	Dates              int `json:"dates"`
+	FavouritesMigrated int `json:"favourites_migrated"`
+	EventsPreserved    int `json:"events_preserved"`
+}
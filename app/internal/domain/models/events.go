@@ -2,13 +2,129 @@ package models
 
 import "time"
 
+// EventPrice is the normalized form of the free text price. PriceMin or PriceMax is nil when the price
+// gives no such bound.
+type EventPrice struct {
+	PriceMin      *float64 `json:"price_min" db:"price_min"`
+	PriceMax      *float64 `json:"price_max" db:"price_max"`
+	PriceCurrency string   `json:"price_currency" db:"price_currency"`
+	IsFree        bool     `json:"is_free" db:"is_free"`
+}
+
+// Event is an item of the listing and favourites responses. Unlike the newer models it has no json tags,
+// clients read its fields by their Go names, the normalized price included.
 type Event struct {
-	Id          string    `db:"id"`
-	UrlImg      string    `db:"url_img"`
-	Label       string    `db:"label"`
-	Description string    `db:"description"`
-	Date        time.Time `db:"date"`
-	Price       string    `db:"price"`
-	UrlBuy      string    `db:"url_buy"`
-	IsFavorite  bool      `db:"is_favorite"`
+	Id            string    `db:"id"`
+	UrlImg        string    `db:"url_img"`
+	Label         string    `db:"label"`
+	Description   string    `db:"description"`
+	DateId        string    `db:"date_id"`
+	Date          time.Time `db:"date"`
+	Price         string    `db:"price"`
+	PriceMin      *float64  `db:"price_min"`
+	PriceMax      *float64  `db:"price_max"`
+	PriceCurrency string    `db:"price_currency"`
+	IsFree        bool      `db:"is_free"`
+	UrlBuy        string    `db:"url_buy"`
+	IsFavorite    bool      `db:"is_favorite"`
+}
+
+type EventDate struct {
+	Id         string    `json:"id" db:"id"`
+	Date       time.Time `json:"date" db:"date"`
+	IsFavorite bool      `json:"is_favorite" db:"is_favorite"`
+}
+
+type EventDetails struct {
+	Id          string `json:"id" db:"id"`
+	IdGroup     string `json:"id_group" db:"id_group"`
+	Src         string `json:"src" db:"src"`
+	Category    string `json:"category" db:"category"`
+	Label       string `json:"label" db:"label"`
+	Description string `json:"description" db:"description"`
+	Price       string `json:"price" db:"price"`
+	EventPrice
+	Url       string      `json:"url" db:"url"`
+	UrlImg    string      `json:"url_img" db:"url_img"`
+	UrlBuy    string      `json:"url_buy" db:"url_buy"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt *time.Time  `json:"updated_at" db:"updated_at"`
+	Dates     []EventDate `json:"dates" db:"-"`
+}
+
+// EventInput is a moderator supplied set of event fields used for create and update.
+type EventInput struct {
+	Src         string
+	Category    string
+	Label       string
+	Description string
+	Price       string
+	EventPrice
+	Url    string
+	UrlImg string
+	UrlBuy string
+	Dates  []time.Time
+}
+
+// EventSearchResult is an event matching a search query together with its nearest date in the range.
+type EventSearchResult struct {
+	Id                 string    `json:"id" db:"id"`
+	Category           string    `json:"category" db:"category"`
+	Label              string    `json:"label" db:"label"`
+	Description        string    `json:"description" db:"description"`
+	LabelSnippet       string    `json:"label_snippet" db:"label_snippet"`
+	DescriptionSnippet string    `json:"description_snippet" db:"description_snippet"`
+	Rank               float64   `json:"rank" db:"rank"`
+	DateId             string    `json:"date_id" db:"date_id"`
+	Date               time.Time `json:"date" db:"date"`
+	Price              string    `json:"price" db:"price"`
+	EventPrice
+	UrlImg     string `json:"url_img" db:"url_img"`
+	UrlBuy     string `json:"url_buy" db:"url_buy"`
+	IsFavorite bool   `json:"is_favorite" db:"is_favorite"`
+}
+
+type EventSearch struct {
+	Query    string
+	Category string
+	DateFrom *time.Time
+	DateTo   *time.Time
+	Limit    int
+	Offset   int
+}
+
+// EventFilter narrows event listings, zero fields do not filter. Date bounds are inclusive and either
+// may be omitted for an open range.
+type EventFilter struct {
+	Categories     []string
+	Sources        []string
+	DateFrom       *time.Time
+	DateTo         *time.Time
+	FreeOnly       bool
+	PriceMin       *float64
+	PriceMax       *float64
+	OnlyFavourites bool
+	Query          string
+}
+
+const (
+	SortDate   = "date"
+	SortPrice  = "price"
+	SortRecent = "recent"
+)
+
+// EventPage selects a page of an event listing. Cursor is the next_cursor of the previous page.
+type EventPage struct {
+	Sort   string
+	Limit  int
+	Cursor string
+}
+
+// EventCursor is the sort key of the last event of a page, the next page starts right after it.
+type EventCursor struct {
+	Sort      string    `json:"s"`
+	Date      time.Time `json:"d"`
+	Price     float64   `json:"p,omitempty"`
+	CreatedAt time.Time `json:"c,omitempty"`
+	DateId    string    `json:"i"`
 }
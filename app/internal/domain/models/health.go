@@ -0,0 +1,18 @@
+package models
+
+const (
+	HealthOK   = "ok"
+	HealthFail = "fail"
+)
+
+type HealthCheck struct {
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// HealthReport is the result of the readiness checks, Status is ok only when every check is ok.
+type HealthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]HealthCheck `json:"checks"`
+}
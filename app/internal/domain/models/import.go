@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// EventImport is a batch of scraped events of one source and category. An import replaces all the
+// actual events of the pair.
+type EventImport struct {
+	Src      string        `json:"src" binding:"required"`
+	Category string        `json:"category" binding:"required"`
+	Events   []ImportEvent `json:"events" binding:"required,min=1"`
+}
+
+type ImportEvent struct {
+	Label       string      `json:"label"`
+	Description string      `json:"description"`
+	Price       string      `json:"price"`
+	Url         string      `json:"url"`
+	UrlImg      string      `json:"url_img"`
+	UrlBuy      string      `json:"url_buy"`
+	Dates       []time.Time `json:"dates"`
+}
+
+// ImportRejection is an event of the batch left out of the import, Index is its position in the batch.
+type ImportRejection struct {
+	Index  int    `json:"index"`
+	Label  string `json:"label"`
+	Reason string `json:"reason"`
+}
+
+type ImportReport struct {
+	IdGroup  string            `json:"id_group,omitempty"`
+	Imported int               `json:"imported"`
+	Rejected []ImportRejection `json:"rejected"`
+}
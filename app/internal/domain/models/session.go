@@ -9,7 +9,22 @@ type RegSession struct {
 }
 
 type Session struct {
-	UserID      string    `json:"user_id" db:"user_id"`
-	FingerPrint string    `json:"finger_print" db:"finger_print"`
-	ExpiredAt   time.Time `json:"expired_at" db:"exp_at"`
+	ID          string     `json:"id" db:"id"`
+	UserID      string     `json:"user_id" db:"user_id"`
+	FingerPrint string     `json:"finger_print" db:"finger_print"`
+	ExpiredAt   time.Time  `json:"expired_at" db:"exp_at"`
+	IP          string     `json:"ip" db:"ip"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ActiveSession is a session of the signed-in user as shown in the devices list.
+type ActiveSession struct {
+	ID              string     `json:"id"`
+	IP              string     `json:"ip"`
+	Device          string     `json:"device"`
+	CreatedAt       time.Time  `json:"created_at"`
+	LastRefreshedAt *time.Time `json:"last_refreshed_at"`
+	ExpiredAt       time.Time  `json:"expired_at"`
+	Current         bool       `json:"current"`
 }
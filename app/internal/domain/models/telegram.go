@@ -0,0 +1,37 @@
+package models
+
+// TgUpdate is the part of a Telegram Bot API update the backend handles.
+type TgUpdate struct {
+	UpdateID int64      `json:"update_id"`
+	Message  *TgMessage `json:"message"`
+}
+
+type TgMessage struct {
+	MessageID int64   `json:"message_id"`
+	From      *TgUser `json:"from"`
+	Chat      TgChat  `json:"chat"`
+	Text      string  `json:"text"`
+}
+
+type TgUser struct {
+	ID        int64  `json:"id"`
+	IsBot     bool   `json:"is_bot"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Username  string `json:"username"`
+}
+
+type TgChat struct {
+	ID int64 `json:"id"`
+}
+
+// TgWidgetAuth is the signed payload of the Telegram Login Widget.
+type TgWidgetAuth struct {
+	ID        int64  `json:"id" binding:"required"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Username  string `json:"username"`
+	PhotoURL  string `json:"photo_url"`
+	AuthDate  int64  `json:"auth_date" binding:"required"`
+	Hash      string `json:"hash" binding:"required"`
+}
@@ -1,8 +1,22 @@
 package models
 
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+const (
+	PermEventRead  = "event.read"
+	PermEventWrite = "event.write"
+	PermUserRead   = "user.read"
+	PermUserWrite  = "user.write"
+)
+
 type UserDTO struct {
-	Uuid string `json:"uuid" db:"id"`
-	Role string `json:"role"`
+	Uuid      string `json:"uuid" db:"id"`
+	Role      string `json:"role"`
+	SessionID string `json:"-" db:"-"`
 }
 
 type User struct {
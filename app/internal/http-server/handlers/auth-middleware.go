@@ -5,8 +5,8 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/UdinSemen/moscow-events-backend/internal/logger"
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
 )
 
 const (
@@ -18,6 +18,8 @@ const (
 	emptyAuthHeader        = "empty auth header"
 	invalidAuthHeader      = "invalid auth header"
 	invalidToken           = "invalid access token"
+	accessDenied           = "access denied"
+	revokedToken           = "access token revoked"
 )
 
 func (h *Handler) userIdentity(c *gin.Context) {
@@ -37,15 +39,51 @@ func (h *Handler) userIdentity(c *gin.Context) {
 
 	user, err := h.jwtManager.ParseToken(headerParts[1])
 	if err != nil {
-		zap.S().Infof(fmt.Sprintf(invalidAuth, user.Uuid, user.Role))
-		zap.S().Warn(fmt.Errorf("%s:%w", op, err))
+		logger.FromContext(c).Sugar().Infof(fmt.Sprintf(invalidAuth, user.Uuid, user.Role))
+		logger.FromContext(c).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
 		newErrorResponse(c, http.StatusUnauthorized, invalidToken)
 		return
 	}
 
-	zap.S().Infof(fmt.Sprintf(okayAuth, user.Uuid, user.Role))
+	revoked, err := h.service.Auth.IsSessionRevoked(c, user.SessionID)
+	if err != nil {
+		logger.FromContext(c).Sugar().Error(fmt.Errorf("%s:%w", op, err))
+		newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		return
+	}
+	if revoked {
+		logger.FromContext(c).Sugar().Infof(fmt.Sprintf(invalidAuth, user.Uuid, user.Role))
+		newErrorResponse(c, http.StatusUnauthorized, revokedToken)
+		return
+	}
+
+	logger.FromContext(c).Sugar().Infof(fmt.Sprintf(okayAuth, user.Uuid, user.Role))
 	c.Set(UserCtx, user)
 }
-func (h *Handler) adminIdentity(c *gin.Context) {
-	// todo implement me
+
+// adminIdentity returns a middleware that lets through only users whose role grants all permissions.
+// It must be placed after userIdentity.
+func (h *Handler) adminIdentity(permissions ...string) gin.HandlerFunc {
+	const op = opPrefixAuthMiddleware + "adminIdentity"
+
+	return func(c *gin.Context) {
+		user, err := getUserDTOFromCtx(c)
+		if err != nil {
+			logger.FromContext(c).Sugar().Error(fmt.Errorf("%s:%w", op, err))
+			return
+		}
+
+		ok, err := h.service.Access.HasPermissions(c, user.Role, permissions...)
+		if err != nil {
+			logger.FromContext(c).Sugar().Error(fmt.Errorf("%s:%w", op, err))
+			newErrorResponse(c, http.StatusInternalServerError, internalErr)
+			return
+		}
+
+		if !ok {
+			logger.FromContext(c).Sugar().Infof(fmt.Sprintf(invalidAuth, user.Uuid, user.Role))
+			newErrorResponse(c, http.StatusForbidden, accessDenied)
+			return
+		}
+	}
 }
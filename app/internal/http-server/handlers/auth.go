@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
-	logmiddlewares "github.com/UdinSemen/moscow-events-backend/internal/http-server/log-middlewares"
+	"github.com/UdinSemen/moscow-events-backend/internal/logger"
+	"github.com/UdinSemen/moscow-events-backend/internal/metrics"
 	"github.com/UdinSemen/moscow-events-backend/internal/services"
 	storage "github.com/UdinSemen/moscow-events-backend/internal/storage/postgres"
 	"github.com/gin-gonic/gin"
@@ -21,10 +25,12 @@ const (
 	differentFingerprint = "different fingerprint"
 	refreshTokenExpired  = "refresh token expired"
 	notExistSession      = "not exist session"
-	timeOutWs            = 3
+	refreshTokenReused   = "refresh token reused, session revoked"
+	tooManyAttempts      = "too many sign in attempts"
+	invalidTimeCode      = "invalid time code"
+	sessionNotConfirmed  = "session not confirmed"
 	errTimeout           = "timeout"
 	opPrefixHandlers     = "http-server.handlers."
-	nameFieldReqIDLog    = "req_id"
 )
 
 var (
@@ -32,7 +38,6 @@ var (
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 	}
-	ErrReqIdNotExist = errors.New("request id not exist")
 )
 
 type inputSignUp struct {
@@ -42,19 +47,18 @@ type inputSignUp struct {
 func (h *Handler) signUp(c *gin.Context) {
 	const op = opPrefixHandlers + "signUp"
 
-	// todo req id
-	zap.S().Info(c.RemoteIP())
+	logger.FromContext(c).Sugar().Info(c.ClientIP())
 	var input inputSignUp
 	if err := c.BindJSON(&input); err != nil {
-		zap.S().Warn(fmt.Errorf("%s:%w", op, err))
+		logger.FromContext(c).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
 		newErrorResponse(c, http.StatusBadRequest, errBindingJSON.Error())
 		return
 	}
-	zap.S().Info(input)
+	logger.FromContext(c).Sugar().Info(input)
 
 	timeCode, err := h.service.Auth.CreateRegSession(c, input.FingerPrint)
 	if err != nil {
-		zap.S().Warn(fmt.Errorf("%s:%w", op, err))
+		logger.FromContext(c).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
 		newErrorResponse(c, http.StatusInternalServerError, internalErr)
 		return
 	}
@@ -77,58 +81,71 @@ type outputSignIn struct {
 func (h *Handler) signIn(c *gin.Context) {
 	const op = "http-server.handlers.signIn"
 
-	reqId, ok := c.Get(logmiddlewares.RequestIDCtx)
-	if !ok {
-		zap.S().Errorf("%s:%v", op, ErrReqIdNotExist)
-	}
-
-	zap.S().Info(c.RemoteIP())
+	logger.FromContext(c).Sugar().Info(c.ClientIP())
 	var input inputSignIn
 
 	if err := c.BindJSON(&input); err != nil {
-		zap.S().Warn(fmt.Errorf("%s:%w", op, err))
+		logger.FromContext(c).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
 		newErrorResponse(c, http.StatusBadRequest, errBindingJSON.Error())
 		return
 	}
 
-	zap.S().Info(input)
+	logger.FromContext(c).Sugar().Info(input)
 
-	userTgId, err := h.service.Auth.GetRegSession(c, input.FingerPrint, input.TimeCode)
+	userTgId, err := h.service.Auth.GetRegSession(c, input.FingerPrint, input.TimeCode, c.ClientIP())
 	if err != nil {
-		zap.S().Warn(fmt.Errorf("%s:%w", op, err))
-		newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		logger.FromContext(c).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
+		metrics.SignIns.WithLabelValues(metrics.SignInCode, authReason(err)).Inc()
+		var attemptsErr *services.TooManyAttemptsError
+		switch {
+		case errors.As(err, &attemptsErr):
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(attemptsErr.RetryAfter.Seconds()))))
+			newErrorResponse(c, http.StatusTooManyRequests, tooManyAttempts)
+		case errors.Is(err, services.ErrNoRegSession), errors.Is(err, services.ErrInvalidFingerPrint):
+			newErrorResponse(c, http.StatusUnauthorized, invalidTimeCode)
+		case errors.Is(err, services.ErrSessionNotConfirmed):
+			newErrorResponse(c, http.StatusForbidden, sessionNotConfirmed)
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		}
 		return
 	}
 
 	userDTO, err := h.service.Auth.GetUserDTOByTg(c, userTgId)
 	if err != nil {
-		zap.L().Error(op,
+		logger.FromContext(c).Error(op,
 			zap.Error(err),
 			zap.String("user_tg_id", userTgId),
-			zap.Any("req_id", reqId),
 		)
 		newErrorResponse(c, http.StatusInternalServerError, internalErr)
 		return
 	}
 
-	accessToken, refreshToken, err := h.service.Auth.GenerateTokens(c, userDTO.Uuid, userDTO.Role)
+	sessionID, err := h.service.Auth.NewSessionID(c)
+	if err != nil {
+		logger.FromContext(c).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
+		newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		return
+	}
+
+	accessToken, refreshToken, err := h.service.Auth.GenerateTokens(c, userDTO.Uuid, userDTO.Role, sessionID)
 	if err != nil {
-		zap.L().Error(op,
+		logger.FromContext(c).Error(op,
 			zap.Error(err),
 			zap.String("user_id", userDTO.Uuid),
-			zap.Any("req_id", reqId),
 		)
 		newErrorResponse(c, http.StatusInternalServerError, internalErr)
 		return
 	}
 
-	err = h.service.InitSession(c, userTgId, refreshToken, c.RemoteIP(), input.FingerPrint)
+	err = h.service.InitSession(c, sessionID, userTgId, refreshToken, c.ClientIP(), input.FingerPrint)
 	if err != nil {
-		zap.S().Warn(fmt.Errorf("%s:%w", op, err))
+		logger.FromContext(c).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
 		newErrorResponse(c, http.StatusInternalServerError, internalErr)
 		return
 	}
 
+	metrics.SignIns.WithLabelValues(metrics.SignInCode, metrics.ReasonSuccess).Inc()
 	c.JSON(http.StatusOK, outputSignIn{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
@@ -138,118 +155,214 @@ func (h *Handler) signIn(c *gin.Context) {
 func (h *Handler) signInWebSocket(c *gin.Context) {
 	const op = opPrefixHandlers + "signInWebSocket"
 
-	reqId, ok := c.Get(logmiddlewares.RequestIDCtx)
-	if !ok {
-		zap.S().Errorf("%s:%v", op, ErrReqIdNotExist)
-	}
-
 	con, err := connUpgrade.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
-		zap.S().Warn(fmt.Errorf("%s:%w", op, err))
+		logger.FromContext(c).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
 		newErrorResponse(c, http.StatusBadRequest, errBindingJSON.Error())
 		return
 	}
-	zap.S().Info(con.RemoteAddr())
+	logger.FromContext(c).Sugar().Info(con.RemoteAddr())
 
 	var input inputSignIn
 	if err := con.ReadJSON(&input); err != nil {
-		zap.S().Warn(fmt.Errorf("%s:%w", op, err))
+		logger.FromContext(c).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
 
 		if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
 
-			zap.L().Warn("normal closure",
+			logger.FromContext(c).Warn("normal closure",
 				zap.Error(err),
-				zap.Any("req_id", reqId),
 			)
 		}
 		if err := newErrorWsResponse(con, websocket.CloseInternalServerErr, internalErr); err != nil {
-			zap.S().Error(fmt.Errorf("%s:%w", op, err))
+			logger.FromContext(c).Sugar().Error(fmt.Errorf("%s:%w", op, err))
 		}
 		return
 	}
 
-	zap.S().Info(input)
+	logger.FromContext(c).Sugar().Info(input)
 
-	userChanel := make(chan models.UserDTO)
+	// the client isn't expected to send anything else, a failed read means it has gone away
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
 	go func() {
-		counter := 1
+		defer cancel()
 		for {
-			zap.S().Debug(counter)
-			if counter > timeOutWs {
-				if err := newErrorWsResponse(con, websocket.CloseTryAgainLater, errTimeout); err != nil {
-					zap.S().Error(fmt.Errorf("%s:%w", op, err))
-				}
+			if _, _, err := con.ReadMessage(); err != nil {
 				return
 			}
-			userTgId, err := h.service.Auth.GetRegSession(c, input.FingerPrint, input.TimeCode)
-			if err != nil {
-				counter++
-				if errors.Is(err, services.ErrSessionNotConfirmed) {
-					if counter == timeOutWs {
-						zap.L().Warn(op,
-							zap.Error(err),
-							zap.String("user_tg_id", userTgId),
-							zap.Any("req_id", reqId),
-						)
-					}
-				} else {
-					zap.S().Warn(fmt.Errorf("%s:%w", op, err))
-					if err := newErrorWsResponse(con, websocket.CloseTryAgainLater, internalErr); err != nil {
-						zap.S().Error(fmt.Errorf("%s:%w", op, err))
-					}
-					return
-				}
-			} else {
-				if userTgId != "" {
-					userDTO, err := h.service.Auth.GetUserDTOByTg(c, userTgId)
-					if err != nil {
-						zap.L().Error(op,
-							zap.Error(err),
-							zap.String("user_tg_id", userTgId),
-							zap.Any("req_id", reqId),
-						)
-					}
-					userChanel <- userDTO
-				}
-			}
-			time.Sleep(time.Second)
 		}
 	}()
 
-	userDTO := <-userChanel
+	metrics.WebSocketWaits.Inc()
+	waitStart := time.Now()
+	userTgId, err := h.service.Auth.WaitRegSession(ctx, input.FingerPrint, input.TimeCode, c.ClientIP())
+	metrics.WebSocketWaits.Dec()
+	waitReason := metrics.ReasonSuccess
+	if err != nil {
+		waitReason = authReason(err)
+	}
+	metrics.WebSocketWaitDuration.WithLabelValues(waitReason).Observe(time.Since(waitStart).Seconds())
+	if err != nil {
+		metrics.SignIns.WithLabelValues(metrics.SignInWebSocket, waitReason).Inc()
+		var message string
+		switch {
+		case errors.Is(err, context.Canceled):
+			logger.FromContext(c).Info(op,
+				zap.Error(err),
+			)
+			_ = con.Close()
+			return
+		case errors.Is(err, services.ErrRegSessionTimeout):
+			message = errTimeout
+		case errors.Is(err, services.ErrTooManyAttempts):
+			message = tooManyAttempts
+		case errors.Is(err, services.ErrNoRegSession), errors.Is(err, services.ErrInvalidFingerPrint):
+			message = invalidTimeCode
+		default:
+			message = internalErr
+		}
+		logger.FromContext(c).Warn(op,
+			zap.Error(err),
+		)
+		if err := newErrorWsResponse(con, websocket.CloseTryAgainLater, message); err != nil {
+			logger.FromContext(c).Sugar().Error(fmt.Errorf("%s:%w", op, err))
+		}
+		return
+	}
 
-	accessToken, refreshToken, err := h.service.Auth.GenerateTokens(c, userDTO.Uuid, userDTO.Role)
+	userDTO, err := h.service.Auth.GetUserDTOByTg(ctx, userTgId)
 	if err != nil {
-		zap.L().Error(op,
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+			zap.String("user_tg_id", userTgId),
+		)
+		if err := newErrorWsResponse(con, websocket.CloseInternalServerErr, internalErr); err != nil {
+			logger.FromContext(c).Sugar().Error(fmt.Errorf("%s:%w", op, err))
+		}
+		return
+	}
+
+	sessionID, err := h.service.Auth.NewSessionID(ctx)
+	if err != nil {
+		logger.FromContext(c).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
+		if err := newErrorWsResponse(con, websocket.CloseInternalServerErr, internalErr); err != nil {
+			logger.FromContext(c).Sugar().Error(fmt.Errorf("%s:%w", op, err))
+		}
+		return
+	}
+
+	accessToken, refreshToken, err := h.service.Auth.GenerateTokens(ctx, userDTO.Uuid, userDTO.Role, sessionID)
+	if err != nil {
+		logger.FromContext(c).Error(op,
 			zap.Error(err),
 			zap.String("user_id", userDTO.Uuid),
-			zap.Any("req_id", reqId),
 		)
+		if err := newErrorWsResponse(con, websocket.CloseInternalServerErr, internalErr); err != nil {
+			logger.FromContext(c).Sugar().Error(fmt.Errorf("%s:%w", op, err))
+		}
+		return
 	}
 
-	err = h.service.InitSession(c, userDTO.Uuid, refreshToken, c.RemoteIP(), input.FingerPrint)
+	err = h.service.InitSession(ctx, sessionID, userTgId, refreshToken, c.ClientIP(), input.FingerPrint)
 	if err != nil {
-		zap.S().Warn(fmt.Errorf("%s:%w", op, err))
-		if err := con.WriteMessage(websocket.CloseInternalServerErr, []byte(internalErr)); err != nil {
-			zap.S().Error(fmt.Errorf("%s:%w", op, err))
+		logger.FromContext(c).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
+		if err := newErrorWsResponse(con, websocket.CloseInternalServerErr, internalErr); err != nil {
+			logger.FromContext(c).Sugar().Error(fmt.Errorf("%s:%w", op, err))
 		}
 		return
 	}
 
+	metrics.SignIns.WithLabelValues(metrics.SignInWebSocket, metrics.ReasonSuccess).Inc()
 	if err := con.WriteJSON(outputSignIn{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 	}); err != nil {
-		zap.S().Warn(fmt.Errorf("%s:%w", op, err))
-		if err := con.WriteMessage(websocket.CloseInternalServerErr, []byte(internalErr)); err != nil {
-			zap.L().Error(op,
+		logger.FromContext(c).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
+		_ = con.Close()
+		return
+	}
+
+	if err := newErrorWsResponse(con, websocket.CloseNormalClosure, ""); err != nil {
+		logger.FromContext(c).Warn(op,
+			zap.Error(err),
+		)
+	}
+}
+
+type inputTelegramWidget struct {
+	models.TgWidgetAuth
+	FingerPrint string `json:"finger_print" binding:"required"`
+}
+
+func (h *Handler) signInTelegramWidget(c *gin.Context) {
+	const op = opPrefixHandlers + "signInTelegramWidget"
+
+	var input inputTelegramWidget
+	if err := c.BindJSON(&input); err != nil {
+		logger.FromContext(c).Warn(op,
+			zap.Error(err),
+		)
+		newErrorResponse(c, http.StatusBadRequest, errBindingJSON.Error())
+		return
+	}
+
+	userDTO, err := h.service.Telegram.AuthWidget(c, input.TgWidgetAuth)
+	if err != nil {
+		metrics.SignIns.WithLabelValues(metrics.SignInTelegramWidget, authReason(err)).Inc()
+		switch {
+		case errors.Is(err, services.ErrInvalidWidgetHash):
+			logger.FromContext(c).Warn(op,
+				zap.Error(err),
+				zap.Int64("user_tg_id", input.ID),
+			)
+			newErrorResponse(c, http.StatusUnauthorized, services.ErrInvalidWidgetHash.Error())
+		case errors.Is(err, services.ErrWidgetAuthExpired):
+			newErrorResponse(c, http.StatusUnauthorized, services.ErrWidgetAuthExpired.Error())
+		case errors.Is(err, services.ErrWidgetAuthFuture):
+			logger.FromContext(c).Warn(op,
 				zap.Error(err),
-				zap.Any("req_id", reqId),
+				zap.Int64("user_tg_id", input.ID),
 			)
+			newErrorResponse(c, http.StatusUnauthorized, services.ErrWidgetAuthFuture.Error())
+		default:
+			logger.FromContext(c).Error(op,
+				zap.Error(err),
+				zap.Int64("user_tg_id", input.ID),
+			)
+			newErrorResponse(c, http.StatusInternalServerError, internalErr)
 		}
+		return
 	}
 
-	zap.S().Error(fmt.Errorf("%s:%v", op, con.Close()))
+	sessionID, err := h.service.Auth.NewSessionID(c)
+	if err != nil {
+		logger.FromContext(c).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
+		newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		return
+	}
+
+	accessToken, refreshToken, err := h.service.Auth.GenerateTokens(c, userDTO.Uuid, userDTO.Role, sessionID)
+	if err != nil {
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+			zap.String("user_id", userDTO.Uuid),
+		)
+		newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		return
+	}
+
+	userTgId := strconv.FormatInt(input.ID, 10)
+	if err := h.service.Auth.InitSession(c, sessionID, userTgId, refreshToken, c.ClientIP(), input.FingerPrint); err != nil {
+		logger.FromContext(c).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
+		newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		return
+	}
+
+	metrics.SignIns.WithLabelValues(metrics.SignInTelegramWidget, metrics.ReasonSuccess).Inc()
+	c.JSON(http.StatusOK, outputSignIn{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	})
 }
 
 type inputRefresh struct {
@@ -265,75 +378,152 @@ type outputRefresh struct {
 func (h *Handler) refresh(c *gin.Context) {
 	const op = opPrefixHandlers + "refresh"
 
-	reqId, ok := c.Get(logmiddlewares.RequestIDCtx)
-	if !ok {
-		zap.S().Errorf("%s:%v", op, ErrReqIdNotExist)
-	}
-
 	var input inputRefresh
 	if err := c.BindJSON(&input); err != nil {
-		zap.L().Warn(op,
+		logger.FromContext(c).Warn(op,
 			zap.Error(err),
-			zap.Any("req_id", reqId),
 		)
 		newErrorResponse(c, http.StatusBadRequest, errBindingJSON.Error())
 		return
 	}
 
-	zap.S().Debug(input)
+	logger.FromContext(c).Sugar().Debug(input)
 
-	accessToken, refreshToken, err := h.service.Auth.RefreshToken(c, input.RefreshToken, input.FingerPrint)
+	accessToken, refreshToken, err := h.service.Auth.RefreshToken(c, input.RefreshToken, input.FingerPrint, c.ClientIP())
 	if err != nil {
+		metrics.Refreshes.WithLabelValues(authReason(err)).Inc()
 		switch {
 		case errors.Is(err, services.ErrInvalidFingerPrint):
-			zap.L().Warn(op,
+			logger.FromContext(c).Warn(op,
 				zap.Error(err),
-				zap.Any("req_id", reqId),
 			)
 			newErrorResponse(c, http.StatusBadRequest, invalidFingerprint)
 			return
 		case errors.Is(err, services.ErrDifferentFingerPrint):
-			zap.L().Warn(op,
+			logger.FromContext(c).Warn(op,
 				zap.Error(err),
-				zap.Any("req_id", reqId),
 			)
 			newErrorResponse(c, http.StatusBadRequest, differentFingerprint)
 			return
 		case errors.Is(err, services.ErrRefreshTokenExp):
-			zap.L().Warn(op,
+			logger.FromContext(c).Warn(op,
 				zap.Error(err),
-				zap.Any("req_id", reqId),
 			)
 			newErrorResponse(c, http.StatusBadRequest, refreshTokenExpired)
 			return
+		case errors.Is(err, services.ErrRefreshTokenReused):
+			logger.FromContext(c).Warn(op,
+				zap.Error(err),
+			)
+			newErrorResponse(c, http.StatusUnauthorized, refreshTokenReused)
+			return
 		case errors.Is(err, storage.ErrNoRows):
-			zap.L().Warn(op,
+			logger.FromContext(c).Warn(op,
 				zap.Error(err),
-				zap.Any("req_id", reqId),
 			)
 			newErrorResponse(c, http.StatusBadRequest, notExistSession)
 			return
 		default:
-			zap.L().Error(op,
+			logger.FromContext(c).Error(op,
 				zap.Error(err),
-				zap.Any("req_id", reqId),
 			)
 			newErrorResponse(c, http.StatusInternalServerError, internalErr)
 			return
 		}
 	}
 
-	if err := h.service.Auth.RefreshSession(c, input.RefreshToken, refreshToken, c.RemoteIP()); err != nil {
-		zap.L().Error(op,
+	if err := h.service.Auth.RefreshSession(c, input.RefreshToken, refreshToken, c.ClientIP()); err != nil {
+		metrics.Refreshes.WithLabelValues(authReason(err)).Inc()
+		if errors.Is(err, storage.ErrNoRows) {
+			logger.FromContext(c).Warn(op,
+				zap.Error(err),
+			)
+			newErrorResponse(c, http.StatusBadRequest, notExistSession)
+			return
+		}
+		logger.FromContext(c).Error(op,
 			zap.Error(err),
-			zap.Any("req_id", reqId),
 		)
 		newErrorResponse(c, http.StatusInternalServerError, internalErr)
 		return
 	}
 
+	metrics.Refreshes.WithLabelValues(metrics.ReasonSuccess).Inc()
 	c.JSON(http.StatusOK, outputRefresh{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 	})
 }
+
+type inputLogout struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (h *Handler) logout(c *gin.Context) {
+	const op = opPrefixHandlers + "logout"
+
+	userDTO, err := getUserDTOFromCtx(c)
+	if err != nil {
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+		)
+		return
+	}
+
+	// the body is optional, it's only needed for access tokens without a session id
+	var input inputLogout
+	if c.Request.ContentLength > 0 {
+		if err := c.BindJSON(&input); err != nil {
+			logger.FromContext(c).Warn(op,
+				zap.Error(err),
+			)
+			newErrorResponse(c, http.StatusBadRequest, errBindingJSON.Error())
+			return
+		}
+	}
+
+	if err := h.service.Auth.Logout(c, userDTO, input.RefreshToken); err != nil {
+		switch {
+		case errors.Is(err, services.ErrNoSessionToRevoke):
+			newErrorResponse(c, http.StatusBadRequest, services.ErrNoSessionToRevoke.Error())
+		case errors.Is(err, storage.ErrNoRows):
+			newErrorResponse(c, http.StatusNotFound, notExistSession)
+		default:
+			logger.FromContext(c).Error(op,
+				zap.Error(err),
+				zap.String("user_id", userDTO.Uuid),
+			)
+			newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+func (h *Handler) logoutAll(c *gin.Context) {
+	const op = opPrefixHandlers + "logoutAll"
+
+	userDTO, err := getUserDTOFromCtx(c)
+	if err != nil {
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+		)
+		return
+	}
+
+	if err := h.service.Auth.LogoutAll(c, userDTO.Uuid); err != nil {
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+			zap.String("user_id", userDTO.Uuid),
+		)
+		newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
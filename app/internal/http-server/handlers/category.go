@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/logger"
+	"github.com/UdinSemen/moscow-events-backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const categoriesDefaultWindow = 30 * 24 * time.Hour
+
+type inputGetCategories struct {
+	DateFrom *time.Time `form:"date_from" time_format:"2006-01-02"`
+	DateTo   *time.Time `form:"date_to" time_format:"2006-01-02"`
+}
+
+type outputGetCategories struct {
+	Categories []models.Category `json:"categories"`
+}
+
+func (h *Handler) getCategories(c *gin.Context) {
+	const op = opPrefixHandlers + "getCategories"
+
+	var input inputGetCategories
+	if err := c.BindQuery(&input); err != nil {
+		logger.FromContext(c).Warn(op,
+			zap.Error(err),
+		)
+		newErrorResponse(c, http.StatusBadRequest, invalidQuery)
+		return
+	}
+
+	// upcoming events of the next month by default
+	now := time.Now().UTC()
+	dateFrom := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if input.DateFrom != nil {
+		dateFrom = *input.DateFrom
+	}
+	dateTo := dateFrom.Add(categoriesDefaultWindow)
+	if input.DateTo != nil {
+		dateTo = *input.DateTo
+	}
+
+	categories, err := h.service.Category.GetCategories(c, dateFrom, dateTo)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRange) {
+			newErrorResponse(c, http.StatusBadRequest, services.ErrInvalidRange.Error())
+			return
+		}
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+		)
+		newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, outputGetCategories{
+		Categories: categories,
+	})
+}
@@ -6,57 +6,108 @@ import (
 	"time"
 
 	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
-	logmiddlewares "github.com/UdinSemen/moscow-events-backend/internal/http-server/log-middlewares"
+	"github.com/UdinSemen/moscow-events-backend/internal/logger"
+	"github.com/UdinSemen/moscow-events-backend/internal/services"
 	storage "github.com/UdinSemen/moscow-events-backend/internal/storage/postgres"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 const (
-	NothingWasFound = "nothing was found"
+	NothingWasFound    = "nothing was found"
+	searchDefaultLimit = 20
 )
 
+// inputGetEvent still accepts the single category and the 1-2 dates of the original listing request,
+// they are merged into the filter.
 type inputGetEvent struct {
-	Category string      `json:"category"`
-	Date     []time.Time `json:"date"`
+	Category       string      `json:"category"`
+	Date           []time.Time `json:"date" binding:"max=2"`
+	Categories     []string    `json:"categories"`
+	Sources        []string    `json:"sources"`
+	DateFrom       *time.Time  `json:"date_from"`
+	DateTo         *time.Time  `json:"date_to"`
+	FreeOnly       bool        `json:"free_only"`
+	PriceMin       *float64    `json:"price_min"`
+	PriceMax       *float64    `json:"price_max"`
+	OnlyFavourites bool        `json:"only_favourites"`
+	Query          string      `json:"query"`
+	Sort           string      `json:"sort" binding:"omitempty,oneof=date price recent"`
+	Limit          int         `json:"limit" binding:"omitempty,min=1,max=200"`
+	Cursor         string      `json:"cursor"`
+}
+
+func (i inputGetEvent) filter() models.EventFilter {
+	filter := models.EventFilter{
+		Categories:     i.Categories,
+		Sources:        i.Sources,
+		DateFrom:       i.DateFrom,
+		DateTo:         i.DateTo,
+		FreeOnly:       i.FreeOnly,
+		PriceMin:       i.PriceMin,
+		PriceMax:       i.PriceMax,
+		OnlyFavourites: i.OnlyFavourites,
+		Query:          i.Query,
+	}
+	if i.Category != "" {
+		filter.Categories = append(filter.Categories, i.Category)
+	}
+	switch len(i.Date) {
+	case 1:
+		filter.DateFrom, filter.DateTo = &i.Date[0], &i.Date[0]
+	case 2:
+		filter.DateFrom, filter.DateTo = &i.Date[0], &i.Date[1]
+	}
+	return filter
 }
 
 type outputGetEvent struct {
-	Events []models.Event `json:"events"`
+	Events     []models.Event `json:"events"`
+	NextCursor string         `json:"next_cursor,omitempty"`
 }
 
 func (h *Handler) getEvent(c *gin.Context) {
 	const op = opPrefixHandlers + "getEvent"
 
-	reqId, ok := c.Get(logmiddlewares.RequestIDCtx)
-	if !ok {
-		zap.S().Errorf("%s:%v", op, ErrReqIdNotExist)
-	}
-
 	userDTO, err := getUserDTOFromCtx(c)
 	if err != nil {
-		zap.L().Error(op,
+		logger.FromContext(c).Error(op,
 			zap.Error(err),
-			zap.Any(nameFieldReqIDLog, reqId),
 		)
 		return
 	}
 
 	var input inputGetEvent
 	if err := c.BindJSON(&input); err != nil {
-		zap.L().Warn(op,
+		logger.FromContext(c).Warn(op,
 			zap.Error(err),
-			zap.Any(nameFieldReqIDLog, reqId),
 		)
 		newErrorResponse(c, http.StatusBadRequest, errBindingJSON.Error())
 		return
 	}
 
-	events, err := h.service.Event.GetEvents(c, userDTO.Uuid, input.Category, input.Date)
+	events, nextCursor, err := h.service.Event.GetEvents(c, userDTO.Uuid, input.filter(), models.EventPage{
+		Sort:   input.Sort,
+		Limit:  input.Limit,
+		Cursor: input.Cursor,
+	})
 	if err != nil {
-		zap.L().Error(op,
+		if errors.Is(err, services.ErrInvalidCursor) {
+			newErrorResponse(c, http.StatusBadRequest, services.ErrInvalidCursor.Error())
+			return
+		}
+		for _, filterErr := range []error{
+			services.ErrInvalidRange,
+			services.ErrInvalidPriceRange,
+			services.ErrInvalidFilter,
+		} {
+			if errors.Is(err, filterErr) {
+				newErrorResponse(c, http.StatusBadRequest, filterErr.Error())
+				return
+			}
+		}
+		logger.FromContext(c).Error(op,
 			zap.Error(err),
-			zap.Any(nameFieldReqIDLog, reqId),
 		)
 		if errors.Is(err, storage.ErrNoRows) {
 			newErrorResponse(c, http.StatusOK, NothingWasFound)
@@ -67,6 +118,105 @@ func (h *Handler) getEvent(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, outputGetEvent{
+		Events:     events,
+		NextCursor: nextCursor,
+	})
+}
+
+func (h *Handler) getEventByID(c *gin.Context) {
+	const op = opPrefixHandlers + "getEventByID"
+
+	userDTO, err := getUserDTOFromCtx(c)
+	if err != nil {
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+		)
+		return
+	}
+
+	eventID := c.Param("id")
+	if !uuidRegexp.MatchString(eventID) {
+		newErrorResponse(c, http.StatusBadRequest, invalidEventID)
+		return
+	}
+
+	event, err := h.service.Event.GetEvent(c, userDTO.Uuid, eventID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoRows) {
+			newErrorResponse(c, http.StatusNotFound, eventNotFound)
+			return
+		}
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+			zap.String(nameFieldEventID, eventID),
+		)
+		newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
+type inputSearchEvents struct {
+	Query    string     `form:"q" binding:"required"`
+	Category string     `form:"category"`
+	DateFrom *time.Time `form:"date_from" time_format:"2006-01-02"`
+	DateTo   *time.Time `form:"date_to" time_format:"2006-01-02"`
+	Limit    int        `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset   int        `form:"offset" binding:"omitempty,min=0"`
+}
+
+type outputSearchEvents struct {
+	Events []models.EventSearchResult `json:"events"`
+}
+
+func (h *Handler) searchEvents(c *gin.Context) {
+	const op = opPrefixHandlers + "searchEvents"
+
+	userDTO, err := getUserDTOFromCtx(c)
+	if err != nil {
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+		)
+		return
+	}
+
+	var input inputSearchEvents
+	if err := c.BindQuery(&input); err != nil {
+		logger.FromContext(c).Warn(op,
+			zap.Error(err),
+		)
+		newErrorResponse(c, http.StatusBadRequest, invalidQuery)
+		return
+	}
+	if input.Limit == 0 {
+		input.Limit = searchDefaultLimit
+	}
+
+	events, err := h.service.Event.SearchEvents(c, userDTO.Uuid, models.EventSearch{
+		Query:    input.Query,
+		Category: input.Category,
+		DateFrom: input.DateFrom,
+		DateTo:   input.DateTo,
+		Limit:    input.Limit,
+		Offset:   input.Offset,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrEmptyQuery):
+			newErrorResponse(c, http.StatusBadRequest, services.ErrEmptyQuery.Error())
+		case errors.Is(err, services.ErrInvalidRange):
+			newErrorResponse(c, http.StatusBadRequest, services.ErrInvalidRange.Error())
+		default:
+			logger.FromContext(c).Error(op,
+				zap.Error(err),
+			)
+			newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, outputSearchEvents{
 		Events: events,
 	})
 }
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/logger"
+	storage "github.com/UdinSemen/moscow-events-backend/internal/storage/postgres"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	favouritesDefaultLimit = 20
+	favouriteNotFound      = "favourite event date not found"
+)
+
+type inputFavourite struct {
+	EventID string `json:"event_id" binding:"required,uuid"`
+	DateID  string `json:"date_id" binding:"required,uuid"`
+}
+
+type inputGetFavourites struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+type outputGetFavourites struct {
+	Events []models.Event `json:"events"`
+	Total  int            `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+func (h *Handler) addFavourite(c *gin.Context) {
+	const op = opPrefixHandlers + "addFavourite"
+
+	userDTO, err := getUserDTOFromCtx(c)
+	if err != nil {
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+		)
+		return
+	}
+
+	var input inputFavourite
+	if err := c.BindJSON(&input); err != nil {
+		logger.FromContext(c).Warn(op,
+			zap.Error(err),
+		)
+		newErrorResponse(c, http.StatusBadRequest, errBindingJSON.Error())
+		return
+	}
+
+	if err := h.service.Event.AddFavourite(c, userDTO.Uuid, input.EventID, input.DateID); err != nil {
+		if errors.Is(err, storage.ErrNoRows) {
+			newErrorResponse(c, http.StatusNotFound, eventNotFound)
+			return
+		}
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+		)
+		newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+func (h *Handler) removeFavourite(c *gin.Context) {
+	const op = opPrefixHandlers + "removeFavourite"
+
+	userDTO, err := getUserDTOFromCtx(c)
+	if err != nil {
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+		)
+		return
+	}
+
+	var input inputFavourite
+	if err := c.BindJSON(&input); err != nil {
+		logger.FromContext(c).Warn(op,
+			zap.Error(err),
+		)
+		newErrorResponse(c, http.StatusBadRequest, errBindingJSON.Error())
+		return
+	}
+
+	if err := h.service.Event.RemoveFavourite(c, userDTO.Uuid, input.EventID, input.DateID); err != nil {
+		if errors.Is(err, storage.ErrNoRows) {
+			newErrorResponse(c, http.StatusNotFound, favouriteNotFound)
+			return
+		}
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+		)
+		newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+func (h *Handler) getFavourites(c *gin.Context) {
+	const op = opPrefixHandlers + "getFavourites"
+
+	userDTO, err := getUserDTOFromCtx(c)
+	if err != nil {
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+		)
+		return
+	}
+
+	var input inputGetFavourites
+	if err := c.BindQuery(&input); err != nil {
+		logger.FromContext(c).Warn(op,
+			zap.Error(err),
+		)
+		newErrorResponse(c, http.StatusBadRequest, invalidQuery)
+		return
+	}
+	if input.Limit == 0 {
+		input.Limit = favouritesDefaultLimit
+	}
+
+	events, total, err := h.service.Event.GetFavourites(c, userDTO.Uuid, input.Limit, input.Offset)
+	if err != nil {
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+		)
+		newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, outputGetFavourites{
+		Events: events,
+		Total:  total,
+		Limit:  input.Limit,
+		Offset: input.Offset,
+	})
+}
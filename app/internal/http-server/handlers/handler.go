@@ -4,12 +4,17 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
 	logmiddlewares "github.com/UdinSemen/moscow-events-backend/internal/http-server/log-middlewares"
 	jwtmanager "github.com/UdinSemen/moscow-events-backend/internal/jwt-manager"
+	"github.com/UdinSemen/moscow-events-backend/internal/metrics"
 	"github.com/UdinSemen/moscow-events-backend/internal/services"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
+const serviceName = "moscow-events-backend"
+
 type Handler struct {
 	service    *services.Service
 	jwtManager jwtmanager.TokenManager
@@ -24,6 +29,11 @@ func NewHandler(service *services.Service, jwtManager jwtmanager.TokenManager) *
 
 func (h *Handler) InitRoutes() *gin.Engine {
 	router := gin.New()
+	// handlers pass the gin context on, its values fall back to the request context holding the span
+	router.ContextWithFallback = true
+	router.Use(otelgin.Middleware(serviceName), logmiddlewares.RequestID, metrics.HTTP)
+
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	router.GET("/ping_category", func(c *gin.Context) {
 		c.JSON(http.StatusOK, inputGetEvent{
@@ -32,34 +42,56 @@ func (h *Handler) InitRoutes() *gin.Engine {
 		})
 	})
 
+	router.GET("/healthz", h.healthz)
+	router.GET("/readyz", h.readyz)
+
 	auth := router.Group("/auth", logmiddlewares.RequestLogger)
 	{
 		auth.POST("/sign-in", h.signIn)
 		auth.GET("/sign-in-ws", h.signInWebSocket)
+		auth.POST("/telegram-widget", h.signInTelegramWidget)
 		auth.POST("/sign-up", h.signUp)
 		auth.POST("/refresh", h.refresh)
+		auth.POST("/logout", h.userIdentity, h.logout)
+		auth.POST("/logout-all", h.userIdentity, h.logoutAll)
 	}
 
+	router.POST("/telegram/webhook", logmiddlewares.RequestLogger, h.telegramWebhook)
+
 	api := router.Group("/api", logmiddlewares.RequestLogger, h.userIdentity)
 	{
 		event := api.Group("/event")
 		{
 			event.GET("/", h.getEvent)
+			event.GET("/search", h.searchEvents)
+			event.GET("/:id", h.getEventByID)
+		}
+
+		api.GET("/categories", h.getCategories)
+
+		favourites := api.Group("/favourites")
+		{
+			favourites.GET("/", h.getFavourites)
+			favourites.POST("/", h.addFavourite)
+			favourites.DELETE("/", h.removeFavourite)
 		}
 
 		user := api.Group("/user")
 		{
-			user.GET("/", h.moderateGetUser)
-			user.PUT("/", h.moderateAddUser)
+			user.GET("/", h.adminIdentity(models.PermUserRead), h.moderateGetUser)
+			user.PUT("/", h.adminIdentity(models.PermUserWrite), h.moderateAddUser)
+			user.GET("/sessions", h.getSessions)
+			user.DELETE("/sessions/:id", h.revokeSession)
 		}
 	}
 
-	moderate := router.Group("/moderate")
+	moderate := router.Group("/moderate", logmiddlewares.RequestLogger, h.userIdentity)
 	{
-		modEvent := moderate.Group("/event")
+		modEvent := moderate.Group("/event", h.adminIdentity(models.PermEventWrite))
 		{
-			modEvent.GET("/", h.moderateGetEvent)
-			modEvent.POST("/:id", h.moderateAddEvent)
+			modEvent.GET("/:id", h.moderateGetEvent)
+			modEvent.POST("/", h.moderateAddEvent)
+			modEvent.POST("/import", h.moderateImportEvents)
 			modEvent.PUT("/:id", h.moderateUpdateEvent)
 			modEvent.DELETE("/:id", h.moderateDeleteEvent)
 		}
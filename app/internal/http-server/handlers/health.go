@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// healthz is the liveness probe, the process answering is all it checks.
+func (h *Handler) healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, statusResponse{
+		Status: models.HealthOK,
+	})
+}
+
+// readyz is the readiness probe, it fails with 503 while a dependency is down, the schema is behind or
+// the server is shutting down.
+func (h *Handler) readyz(c *gin.Context) {
+	report := h.service.Health.Ready(c)
+	if report.Status != models.HealthOK {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
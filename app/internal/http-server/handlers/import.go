@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/logger"
+	"github.com/UdinSemen/moscow-events-backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// importErrorResponse is the error response carrying the import report, so the rejected events can be fixed.
+type importErrorResponse struct {
+	errorResponse
+	models.ImportReport
+}
+
+// moderateImportEvents replaces the actual events of a source and category with the batch and responds
+// with the import report. A batch without valid events is answered with 422, the error and the report.
+func (h *Handler) moderateImportEvents(c *gin.Context) {
+	const op = opPrefixHandlers + "moderateImportEvents"
+
+	var input models.EventImport
+	if err := c.BindJSON(&input); err != nil {
+		logger.FromContext(c).Warn(op,
+			zap.Error(err),
+		)
+		newErrorResponse(c, http.StatusBadRequest, errBindingJSON.Error())
+		return
+	}
+
+	report, err := h.service.Event.ImportEvents(c, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrEmptyImport):
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, importErrorResponse{
+				errorResponse: errorResponse{services.ErrEmptyImport.Error(),
+					http.StatusUnprocessableEntity, logger.RequestID(c)},
+				ImportReport: report,
+			})
+		case errors.Is(err, services.ErrInvalidImportKey):
+			newErrorResponse(c, http.StatusBadRequest, services.ErrInvalidImportKey.Error())
+		case errors.Is(err, services.ErrImportTooLarge):
+			newErrorResponse(c, http.StatusRequestEntityTooLarge, services.ErrImportTooLarge.Error())
+		default:
+			logger.FromContext(c).Error(op,
+				zap.Error(err),
+			)
+			newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		}
+		return
+	}
+
+	logger.FromContext(c).Info(op,
+		zap.String("src", input.Src),
+		zap.String("category", input.Category),
+		zap.String("id_group", report.IdGroup),
+		zap.Int("imported", report.Imported),
+		zap.Int("rejected", len(report.Rejected)),
+	)
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/services"
+	storage "github.com/UdinSemen/moscow-events-backend/internal/storage/postgres"
+)
+
+const reasonInternal = "internal"
+
+// authReasons label the failures of sign ins and refreshes in metrics.
+var authReasons = []struct {
+	err    error
+	reason string
+}{
+	{services.ErrTooManyAttempts, "too_many_attempts"},
+	{services.ErrNoRegSession, "no_reg_session"},
+	{services.ErrInvalidFingerPrint, "invalid_finger_print"},
+	{services.ErrDifferentFingerPrint, "different_finger_print"},
+	{services.ErrSessionNotConfirmed, "session_not_confirmed"},
+	{services.ErrRegSessionTimeout, "timeout"},
+	{services.ErrInvalidWidgetHash, "invalid_widget_hash"},
+	{services.ErrWidgetAuthExpired, "widget_auth_expired"},
+	{services.ErrWidgetAuthFuture, "widget_auth_future"},
+	{services.ErrRefreshTokenExp, "refresh_token_expired"},
+	{services.ErrRefreshTokenReused, "refresh_token_reused"},
+	{storage.ErrNoRows, "no_session"},
+	{context.Canceled, "canceled"},
+}
+
+func authReason(err error) string {
+	for _, r := range authReasons {
+		if errors.Is(err, r.err) {
+			return r.reason
+		}
+	}
+	return reasonInternal
+}
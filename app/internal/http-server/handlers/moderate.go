@@ -1,20 +1,176 @@
 package handlers
 
-import "github.com/gin-gonic/gin"
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/logger"
+	"github.com/UdinSemen/moscow-events-backend/internal/services"
+	storage "github.com/UdinSemen/moscow-events-backend/internal/storage/postgres"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	eventNotFound    = "event not found"
+	eventExists      = "event with same label already exists"
+	invalidEventID   = "invalid event id"
+	nameFieldEventID = "event_id"
+)
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+type inputModerateEvent struct {
+	Src         string      `json:"src" binding:"required"`
+	Category    string      `json:"category" binding:"required"`
+	Label       string      `json:"label" binding:"required"`
+	Description string      `json:"description" binding:"required"`
+	Price       string      `json:"price" binding:"required"`
+	Url         string      `json:"url" binding:"omitempty,url"`
+	UrlImg      string      `json:"url_img" binding:"required,url"`
+	UrlBuy      string      `json:"url_buy" binding:"omitempty,url"`
+	Dates       []time.Time `json:"dates" binding:"required,min=1"`
+}
+
+func (i inputModerateEvent) toModel() models.EventInput {
+	return models.EventInput{
+		Src:         i.Src,
+		Category:    i.Category,
+		Label:       i.Label,
+		Description: i.Description,
+		Price:       i.Price,
+		Url:         i.Url,
+		UrlImg:      i.UrlImg,
+		UrlBuy:      i.UrlBuy,
+		Dates:       i.Dates,
+	}
+}
+
+type outputModerateAddEvent struct {
+	Id string `json:"id"`
+}
 
 func (h *Handler) moderateGetEvent(c *gin.Context) {
-	// todo implement me
+	const op = opPrefixHandlers + "moderateGetEvent"
+
+	eventID := c.Param("id")
+	if !uuidRegexp.MatchString(eventID) {
+		newErrorResponse(c, http.StatusBadRequest, invalidEventID)
+		return
+	}
+
+	event, err := h.service.Event.GetEventByID(c, eventID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoRows) {
+			newErrorResponse(c, http.StatusNotFound, eventNotFound)
+			return
+		}
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+			zap.String(nameFieldEventID, eventID),
+		)
+		newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
 }
+
 func (h *Handler) moderateAddEvent(c *gin.Context) {
-	// todo implement me
+	const op = opPrefixHandlers + "moderateAddEvent"
+
+	var input inputModerateEvent
+	if err := c.BindJSON(&input); err != nil {
+		logger.FromContext(c).Warn(op,
+			zap.Error(err),
+		)
+		newErrorResponse(c, http.StatusBadRequest, errBindingJSON.Error())
+		return
+	}
+
+	eventID, err := h.service.Event.CreateEvent(c, input.toModel())
+	if err != nil {
+		h.moderateEventError(c, op, "", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, outputModerateAddEvent{
+		Id: eventID,
+	})
 }
 
 func (h *Handler) moderateUpdateEvent(c *gin.Context) {
-	// todo implement me
+	const op = opPrefixHandlers + "moderateUpdateEvent"
+
+	eventID := c.Param("id")
+	if !uuidRegexp.MatchString(eventID) {
+		newErrorResponse(c, http.StatusBadRequest, invalidEventID)
+		return
+	}
+
+	var input inputModerateEvent
+	if err := c.BindJSON(&input); err != nil {
+		logger.FromContext(c).Warn(op,
+			zap.Error(err),
+		)
+		newErrorResponse(c, http.StatusBadRequest, errBindingJSON.Error())
+		return
+	}
+
+	if err := h.service.Event.UpdateEvent(c, eventID, input.toModel()); err != nil {
+		h.moderateEventError(c, op, eventID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
 }
 
 func (h *Handler) moderateDeleteEvent(c *gin.Context) {
-	// todo implement me
+	const op = opPrefixHandlers + "moderateDeleteEvent"
+
+	eventID := c.Param("id")
+	if !uuidRegexp.MatchString(eventID) {
+		newErrorResponse(c, http.StatusBadRequest, invalidEventID)
+		return
+	}
+
+	if err := h.service.Event.DeleteEvent(c, eventID); err != nil {
+		h.moderateEventError(c, op, eventID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+// moderateEventError maps event service errors of the moderator routes to responses.
+func (h *Handler) moderateEventError(c *gin.Context, op, eventID string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidEvent):
+		newErrorResponse(c, http.StatusBadRequest, services.ErrInvalidEvent.Error())
+	case errors.Is(err, services.ErrDuplicateDates):
+		newErrorResponse(c, http.StatusBadRequest, services.ErrDuplicateDates.Error())
+	case errors.Is(err, storage.ErrNoRows):
+		newErrorResponse(c, http.StatusNotFound, eventNotFound)
+	case errors.Is(err, storage.ErrEventExists):
+		logger.FromContext(c).Warn(op,
+			zap.Error(err),
+			zap.String(nameFieldEventID, eventID),
+		)
+		newErrorResponse(c, http.StatusConflict, eventExists)
+	default:
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+			zap.String(nameFieldEventID, eventID),
+		)
+		newErrorResponse(c, http.StatusInternalServerError, internalErr)
+	}
 }
 
 func (h *Handler) moderateGetUser(c *gin.Context) {
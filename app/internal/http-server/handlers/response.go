@@ -4,17 +4,23 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/UdinSemen/moscow-events-backend/internal/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 )
 
+const (
+	invalidQuery = "invalid query parameters"
+)
+
 var (
 	errBindingJSON = errors.New("invalid JSON")
 )
 
 type errorResponse struct {
-	Message string `json:"message"`
-	Status  int    `json:"status"`
+	Message   string `json:"message"`
+	Status    int    `json:"status"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 type statusResponse struct {
@@ -23,7 +29,7 @@ type statusResponse struct {
 
 func newErrorResponse(c *gin.Context, statusCode int, message string) {
 	c.AbortWithStatusJSON(statusCode, errorResponse{message,
-		statusCode})
+		statusCode, logger.RequestID(c)})
 }
 
 func newErrorWsResponse(con *websocket.Conn, statusCode int, message string) error {
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/logger"
+	storage "github.com/UdinSemen/moscow-events-backend/internal/storage/postgres"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	invalidSessionID = "invalid session id"
+)
+
+type outputGetSessions struct {
+	Sessions []models.ActiveSession `json:"sessions"`
+}
+
+func (h *Handler) getSessions(c *gin.Context) {
+	const op = opPrefixHandlers + "getSessions"
+
+	userDTO, err := getUserDTOFromCtx(c)
+	if err != nil {
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+		)
+		return
+	}
+
+	sessions, err := h.service.Auth.GetActiveSessions(c, userDTO)
+	if err != nil {
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+			zap.String("user_id", userDTO.Uuid),
+		)
+		newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, outputGetSessions{
+		Sessions: sessions,
+	})
+}
+
+func (h *Handler) revokeSession(c *gin.Context) {
+	const op = opPrefixHandlers + "revokeSession"
+
+	userDTO, err := getUserDTOFromCtx(c)
+	if err != nil {
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+		)
+		return
+	}
+
+	sessionID := c.Param("id")
+	if !uuidRegexp.MatchString(sessionID) {
+		newErrorResponse(c, http.StatusBadRequest, invalidSessionID)
+		return
+	}
+
+	if err := h.service.Auth.RevokeSession(c, userDTO.Uuid, sessionID); err != nil {
+		if errors.Is(err, storage.ErrNoRows) {
+			newErrorResponse(c, http.StatusNotFound, notExistSession)
+			return
+		}
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+			zap.String("user_id", userDTO.Uuid),
+		)
+		newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	TgSecretHeader     = "X-Telegram-Bot-Api-Secret-Token"
+	invalidTgSecret    = "invalid secret token"
+	nameFieldTgUpdate  = "update_id"
+	invalidTelegramReq = "invalid update"
+)
+
+func (h *Handler) telegramWebhook(c *gin.Context) {
+	const op = opPrefixHandlers + "telegramWebhook"
+
+	if !h.service.Telegram.VerifyWebhookSecret(c.GetHeader(TgSecretHeader)) {
+		newErrorResponse(c, http.StatusUnauthorized, invalidTgSecret)
+		return
+	}
+
+	var update models.TgUpdate
+	if err := c.BindJSON(&update); err != nil {
+		logger.FromContext(c).Warn(op,
+			zap.Error(err),
+		)
+		newErrorResponse(c, http.StatusBadRequest, invalidTelegramReq)
+		return
+	}
+
+	// an error makes telegram deliver the update again later
+	if err := h.service.Telegram.HandleUpdate(c, update); err != nil {
+		logger.FromContext(c).Error(op,
+			zap.Error(err),
+			zap.Int64(nameFieldTgUpdate, update.UpdateID),
+		)
+		newErrorResponse(c, http.StatusInternalServerError, internalErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
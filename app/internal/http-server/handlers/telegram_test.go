@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/config"
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/services"
+	"github.com/UdinSemen/moscow-events-backend/internal/storage"
+	storageRedis "github.com/UdinSemen/moscow-events-backend/internal/storage/redis"
+	"github.com/UdinSemen/moscow-events-backend/internal/telegram"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	testBotToken      = "123:secret"
+	testWebhookSecret = "webhook-secret"
+	testTimeCode      = "123456"
+)
+
+// fakeBotAPI is a local Bot API server collecting the texts the bot sends.
+type fakeBotAPI struct {
+	mu    sync.Mutex
+	texts []string
+}
+
+func (f *fakeBotAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/bot"+testBotToken+"/sendMessage" {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"ok":false,"description":"Not Found"}`))
+		return
+	}
+	var params struct {
+		Text string `json:"text"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&params)
+
+	f.mu.Lock()
+	f.texts = append(f.texts, params.Text)
+	f.mu.Unlock()
+	_, _ = w.Write([]byte(`{"ok":true}`))
+}
+
+func (f *fakeBotAPI) last() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.texts) == 0 {
+		return ""
+	}
+	return f.texts[len(f.texts)-1]
+}
+
+// fakeUsers keeps the telegram users in memory, the other storage methods are not used by the webhook.
+type fakeUsers struct {
+	storage.PgStorage
+	mu    sync.Mutex
+	users map[string]models.User
+}
+
+func (f *fakeUsers) UpsertTgUser(_ context.Context, user models.User) (models.UserDTO, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.users[user.TgUserID] = user
+	return models.UserDTO{Uuid: "uuid-" + user.TgUserID, Role: models.RoleUser}, nil
+}
+
+type webhookEnv struct {
+	router *gin.Engine
+	redis  *storageRedis.Redis
+	users  *fakeUsers
+	bot    *fakeBotAPI
+}
+
+func newWebhookEnv(t *testing.T) *webhookEnv {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	mr := miniredis.RunT(t)
+	cfg := &config.Config{}
+	cfg.Redis.Host, cfg.Redis.Port = mr.Host(), mr.Port()
+	redisStorage := storageRedis.NewRedisClient(cfg)
+	t.Cleanup(func() { _ = redisStorage.Close() })
+
+	bot := &fakeBotAPI{}
+	api := httptest.NewServer(bot)
+	t.Cleanup(api.Close)
+	client, err := telegram.NewClient(api.URL, testBotToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := &fakeUsers{users: make(map[string]models.User)}
+	service := &services.Service{
+		Telegram: services.NewTelegramService(redisStorage, users, client, testBotToken, testWebhookSecret),
+	}
+
+	return &webhookEnv{
+		router: NewHandler(service, nil).InitRoutes(),
+		redis:  redisStorage,
+		users:  users,
+		bot:    bot,
+	}
+}
+
+func (e *webhookEnv) send(t *testing.T, secret string, fromID int64, text string) int {
+	t.Helper()
+
+	body, err := json.Marshal(models.TgUpdate{
+		UpdateID: 1,
+		Message: &models.TgMessage{
+			MessageID: 1,
+			From:      &models.TgUser{ID: fromID, FirstName: "user"},
+			Chat:      models.TgChat{ID: fromID},
+			Text:      text,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/telegram/webhook", bytes.NewReader(body))
+	req.Header.Set(TgSecretHeader, secret)
+	rec := httptest.NewRecorder()
+	e.router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func (e *webhookEnv) regSession(t *testing.T) models.RegSession {
+	t.Helper()
+
+	val, err := e.redis.GetRegSession(context.Background(), testTimeCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var session models.RegSession
+	if err := json.Unmarshal([]byte(val), &session); err != nil {
+		t.Fatal(err)
+	}
+	return session
+}
+
+func TestTelegramWebhook(t *testing.T) {
+	env := newWebhookEnv(t)
+	if err := env.redis.CreateRegSession(context.Background(), "finger-print", testTimeCode); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := env.send(t, "wrong", 100, testTimeCode); code != http.StatusUnauthorized {
+		t.Fatalf("wrong secret: got status %d", code)
+	}
+
+	// an unknown code neither confirms anything nor creates a user
+	if code := env.send(t, testWebhookSecret, 100, "/start 654321"); code != http.StatusOK {
+		t.Fatalf("unknown code: got status %d", code)
+	}
+	if !strings.HasPrefix(env.bot.last(), "Код не найден") {
+		t.Errorf("unknown code: got reply %q", env.bot.last())
+	}
+	if len(env.users.users) != 0 {
+		t.Errorf("unknown code created users %v", env.users.users)
+	}
+
+	if code := env.send(t, testWebhookSecret, 100, "/start "+testTimeCode); code != http.StatusOK {
+		t.Fatalf("confirm: got status %d", code)
+	}
+	if !strings.HasPrefix(env.bot.last(), "Вход подтверждён") {
+		t.Errorf("confirm: got reply %q", env.bot.last())
+	}
+	if session := env.regSession(t); !session.IsConfirmed || session.UserID != "100" {
+		t.Errorf("confirm: got session %+v", session)
+	}
+	if _, ok := env.users.users["100"]; !ok {
+		t.Error("confirm: user is not created")
+	}
+
+	// the same user may send the code again, telegram redelivers updates
+	if code := env.send(t, testWebhookSecret, 100, testTimeCode); code != http.StatusOK {
+		t.Fatalf("repeat: got status %d", code)
+	}
+	if !strings.HasPrefix(env.bot.last(), "Вход подтверждён") {
+		t.Errorf("repeat: got reply %q", env.bot.last())
+	}
+
+	// another user can't take over a confirmed sign in
+	if code := env.send(t, testWebhookSecret, 200, testTimeCode); code != http.StatusOK {
+		t.Fatalf("takeover: got status %d", code)
+	}
+	if !strings.HasPrefix(env.bot.last(), "Код не найден") {
+		t.Errorf("takeover: got reply %q", env.bot.last())
+	}
+	if session := env.regSession(t); session.UserID != "100" {
+		t.Errorf("takeover: session confirmed by %s", session.UserID)
+	}
+	if _, ok := env.users.users["200"]; ok {
+		t.Error("takeover: user is created")
+	}
+}
+
+func TestTelegramWebhookWithoutBot(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := &services.Service{
+		Telegram: services.NewTelegramService(nil, nil, nil, "", testWebhookSecret),
+	}
+	env := &webhookEnv{router: NewHandler(service, nil).InitRoutes()}
+
+	if code := env.send(t, testWebhookSecret, 100, testTimeCode); code != http.StatusUnauthorized {
+		t.Fatalf("got status %d, want %d", code, http.StatusUnauthorized)
+	}
+}
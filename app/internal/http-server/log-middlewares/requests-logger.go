@@ -2,42 +2,52 @@ package log_middlewares
 
 import (
 	"fmt"
+	"regexp"
 	"time"
 
+	"github.com/UdinSemen/moscow-events-backend/internal/logger"
 	httprnd "github.com/UdinSemen/moscow-events-backend/pkg/http-rnd"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 const (
-	RequestIDCtx         = "requestIDCtx"
+	RequestIDHeader      = "X-Request-ID"
 	mes                  = "request_logger"
-	nameFieldReqID       = "request_id"
 	nameFieldPath        = "path"
 	nameFieldIp          = "client_ip"
 	nameFieldTimeProcess = "work_time"
 )
 
-func RequestLogger(c *gin.Context) {
-	const op = "log_middleware.RequestLogger"
+// requestIDRegexp limits an incoming request id to something safe to log and echo back.
+var requestIDRegexp = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
 
-	timeNow := time.Now()
-	reqID, err := httprnd.MakeReqId()
-	if err != nil {
-		zap.S().Errorf("%s:%v", op, err)
+// RequestID takes the request id from the X-Request-ID header or generates a new one, echoes it in the
+// response and puts it in the request context.
+func RequestID(c *gin.Context) {
+	const op = "log_middleware.RequestID"
+
+	reqID := c.GetHeader(RequestIDHeader)
+	if !requestIDRegexp.MatchString(reqID) {
+		var err error
+		if reqID, err = httprnd.MakeReqId(); err != nil {
+			logger.FromContext(c.Request.Context()).Sugar().Errorf("%s:%v", op, err)
+		}
 	}
-	c.Set(RequestIDCtx, reqID)
+	c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), reqID))
+	c.Header(RequestIDHeader, reqID)
+}
+
+// RequestLogger logs the request once it is handled, it must be placed after RequestID.
+func RequestLogger(c *gin.Context) {
+	timeNow := time.Now()
 
 	// call next middleware in stack
 	c.Next()
 
-	logger := zap.L().WithOptions(zap.WithCaller(false))
-
-	logger.Info(mes,
+	logger.FromContext(c.Request.Context()).WithOptions(zap.WithCaller(false)).Info(mes,
 		zap.String(nameFieldPath, c.FullPath()),
 		zap.String(nameFieldIp, c.ClientIP()),
-		zap.String(nameFieldReqID, reqID),
 		zap.String(nameFieldTimeProcess, fmt.Sprintf("%v", time.Since(timeNow.Round(time.Microsecond)))),
 	)
-
 }
@@ -1,38 +1,49 @@
 package jwt_manager
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"math/rand"
 	"time"
 
 	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/pkg/random"
 	"github.com/dgrijalva/jwt-go"
 	"go.uber.org/zap"
 )
 
+const refreshTokenBytes = 32
+
 type TokenManager interface {
-	GenerateToken(userID, role string) (string, error)
+	GenerateToken(userID, role, sessionID string) (string, error)
 	ParseToken(accessToken string) (models.UserDTO, error)
 	NewRefreshToken() (string, error)
+	HashRefreshToken(refreshToken string) string
 }
 
 type Manager struct {
-	signingKey string
-	tokenTTL   time.Duration
+	signingKey     string
+	refreshHashKey string
+	tokenTTL       time.Duration
 }
 
 type tokenClaims struct {
 	jwt.StandardClaims
 	Uuid string `json:"uuid"`
 	Role string `json:"role"`
+	Sid  string `json:"sid"`
 }
 
-func NewManager(signingKey string, tokenTTL *time.Duration) (*Manager, error) {
+func NewManager(signingKey, refreshHashKey string, tokenTTL *time.Duration) (*Manager, error) {
 	const op = "jwt-manager.NewManager"
 	if signingKey == "" {
 		return nil, fmt.Errorf("%s:%w", op, errors.New("empty signing key"))
 	}
+	if refreshHashKey == "" {
+		return nil, fmt.Errorf("%s:%w", op, errors.New("empty refresh token hash key"))
+	}
 	if tokenTTL == nil {
 		return nil, fmt.Errorf("%s:%w", op, errors.New("empty tokenTTL key"))
 	}
@@ -40,11 +51,12 @@ func NewManager(signingKey string, tokenTTL *time.Duration) (*Manager, error) {
 	zap.S().Infow("tokenTTL",
 		"access", tokenTTL)
 	return &Manager{
-		signingKey: signingKey,
-		tokenTTL:   *tokenTTL}, nil
+		signingKey:     signingKey,
+		refreshHashKey: refreshHashKey,
+		tokenTTL:       *tokenTTL}, nil
 }
 
-func (m *Manager) GenerateToken(userID, role string) (string, error) {
+func (m *Manager) GenerateToken(userID, role, sessionID string) (string, error) {
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
 		StandardClaims: jwt.StandardClaims{
@@ -53,6 +65,7 @@ func (m *Manager) GenerateToken(userID, role string) (string, error) {
 		},
 		Uuid: userID,
 		Role: role,
+		Sid:  sessionID,
 	})
 
 	return token.SignedString([]byte(m.signingKey))
@@ -77,22 +90,31 @@ func (m *Manager) ParseToken(accessToken string) (models.UserDTO, error) {
 		return models.UserDTO{}, fmt.Errorf("error get user claims from token")
 	}
 
+	// tokens issued before sessions were bound to them have no sid claim
+	sessionID, _ := claims["sid"].(string)
+
 	return models.UserDTO{
-		Uuid: claims["uuid"].(string),
-		Role: claims["role"].(string),
+		Uuid:      claims["uuid"].(string),
+		Role:      claims["role"].(string),
+		SessionID: sessionID,
 	}, nil
 }
 
 func (m *Manager) NewRefreshToken() (string, error) {
 	const op = "jwt-manager.NewRefreshToken"
-	b := make([]byte, 32)
-
-	s := rand.NewSource(time.Now().Unix())
-	r := rand.New(s)
 
-	if _, err := r.Read(b); err != nil {
+	refreshToken, err := random.Hex(refreshTokenBytes)
+	if err != nil {
 		return "", fmt.Errorf("%s:%w", op, err)
 	}
 
-	return fmt.Sprintf("%x", b), nil
+	return refreshToken, nil
+}
+
+// HashRefreshToken returns the keyed HMAC-SHA256 of a refresh token in hex. Only the hash is stored,
+// so a database dump doesn't reveal usable tokens.
+func (m *Manager) HashRefreshToken(refreshToken string) string {
+	mac := hmac.New(sha256.New, []byte(m.refreshHashKey))
+	mac.Write([]byte(refreshToken))
+	return hex.EncodeToString(mac.Sum(nil))
 }
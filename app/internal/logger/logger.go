@@ -0,0 +1,36 @@
+// Package logger carries the request id in the context and builds loggers that tag every line with
+// the request id and the trace of the context.
+package logger
+
+import (
+	"context"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/tracing"
+	"go.uber.org/zap"
+)
+
+const nameFieldReqID = "req_id"
+
+type requestIDKey struct{}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request id of the context, empty outside of a request.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// FromContext returns the global logger with the request id and the trace ids of the context.
+func FromContext(ctx context.Context) *zap.Logger {
+	fields := tracing.LogFields(ctx)
+	if requestID := RequestID(ctx); requestID != "" {
+		fields = append(fields, zap.String(nameFieldReqID, requestID))
+	}
+	if len(fields) == 0 {
+		return zap.L()
+	}
+	return zap.L().With(fields...)
+}
@@ -0,0 +1,134 @@
+// Package metrics holds the prometheus collectors of the service and exposes them on /metrics.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+)
+
+const namespace = "moscow_events"
+
+// sign in methods
+const (
+	SignInCode           = "code"
+	SignInWebSocket      = "websocket"
+	SignInTelegramWidget = "telegram_widget"
+)
+
+// ReasonSuccess is the reason label of successful sign ins and refreshes.
+const ReasonSuccess = "success"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status.",
+	}, []string{"route", "method", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	SignIns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sign_ins_total",
+		Help:      "Sign in attempts by method and reason, success for the successful ones.",
+	}, []string{"method", "reason"})
+
+	WebSocketWaits = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "websocket_sign_in_waits",
+		Help:      "WebSocket sign ins waiting for the confirmation of their time code.",
+	})
+
+	WebSocketWaitDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "websocket_sign_in_wait_seconds",
+		Help:      "Time WebSocket sign ins waited for the confirmation by reason.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300},
+	}, []string{"reason"})
+
+	Refreshes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_refreshes_total",
+		Help:      "Refresh token rotations by reason, success for the successful ones.",
+	}, []string{"reason"})
+
+	storageDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_query_duration_seconds",
+		Help:      "Latency of storage operations by storage and operation.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"storage", "op"})
+)
+
+// Handler serves the metrics of the default registry.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// HTTP counts and times the requests by the route pattern, so path parameters don't multiply the series.
+// Requests matching no route are labeled by an empty route.
+func HTTP(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	route := c.FullPath()
+	httpRequests.WithLabelValues(route, c.Request.Method, strconv.Itoa(c.Writer.Status())).Inc()
+	httpDuration.WithLabelValues(route, c.Request.Method).Observe(time.Since(start).Seconds())
+}
+
+// ObserveStorage starts timing a storage operation, the returned func records it.
+func ObserveStorage(storage, op string) func() {
+	start := time.Now()
+	return func() {
+		storageDuration.WithLabelValues(storage, op).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RegisterPostgres exports the connection pool stats of the postgres storage.
+func RegisterPostgres(db *sql.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, "postgres"))
+}
+
+// RegisterRedis exports the connection pool stats of the redis storage.
+func RegisterRedis(stats func() *redis.PoolStats) {
+	opts := func(name, help string) prometheus.Opts {
+		return prometheus.Opts{Namespace: namespace, Subsystem: "redis_pool", Name: name, Help: help}
+	}
+	counter := func(name, help string, value func(s *redis.PoolStats) uint32) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts(opts(name, help)),
+			func() float64 { return float64(value(stats())) })
+	}
+	gauge := func(name, help string, value func(s *redis.PoolStats) uint32) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts(opts(name, help)),
+			func() float64 { return float64(value(stats())) })
+	}
+
+	prometheus.MustRegister(
+		counter("hits_total", "Times a free connection was found in the pool.",
+			func(s *redis.PoolStats) uint32 { return s.Hits }),
+		counter("misses_total", "Times a free connection was not found in the pool.",
+			func(s *redis.PoolStats) uint32 { return s.Misses }),
+		counter("timeouts_total", "Times a wait for a connection timed out.",
+			func(s *redis.PoolStats) uint32 { return s.Timeouts }),
+		counter("stale_conns_total", "Stale connections removed from the pool.",
+			func(s *redis.PoolStats) uint32 { return s.StaleConns }),
+		gauge("total_conns", "Connections in the pool.",
+			func(s *redis.PoolStats) uint32 { return s.TotalConns }),
+		gauge("idle_conns", "Idle connections in the pool.",
+			func(s *redis.PoolStats) uint32 { return s.IdleConns }),
+	)
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/storage"
+	"github.com/UdinSemen/moscow-events-backend/internal/tracing"
+)
+
+const (
+	accessServiceOpPrefix = "services.access."
+	permissionsCacheTTL   = time.Minute
+)
+
+type cachedPermissions struct {
+	permissions []string
+	loadedAt    time.Time
+}
+
+// AccessService resolves role permissions from the roles tables, caching them for a short time
+// because every request to a protected group asks for them.
+type AccessService struct {
+	postgres storage.PgStorage
+
+	mu    sync.RWMutex
+	cache map[string]cachedPermissions
+}
+
+func NewAccessService(postgres storage.PgStorage) *AccessService {
+	return &AccessService{
+		postgres: postgres,
+		cache:    make(map[string]cachedPermissions),
+	}
+}
+
+func (s *AccessService) HasPermissions(ctx context.Context, role string, permissions ...string) (bool, error) {
+	const op = accessServiceOpPrefix + "HasPermissions"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if role == "" {
+		role = models.RoleUser
+	}
+
+	granted, err := s.rolePermissions(ctx, role)
+	if err != nil {
+		return false, fmt.Errorf("%s:%w", op, err)
+	}
+
+	for _, permission := range permissions {
+		if !slices.Contains(granted, permission) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (s *AccessService) rolePermissions(ctx context.Context, role string) ([]string, error) {
+	s.mu.RLock()
+	cached, ok := s.cache[role]
+	s.mu.RUnlock()
+	if ok && time.Since(cached.loadedAt) < permissionsCacheTTL {
+		return cached.permissions, nil
+	}
+
+	permissions, err := s.postgres.GetRolePermissions(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[role] = cachedPermissions{
+		permissions: permissions,
+		loadedAt:    time.Now(),
+	}
+	s.mu.Unlock()
+
+	return permissions, nil
+}
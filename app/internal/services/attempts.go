@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	signInFailuresWindow    = 15 * time.Minute
+	maxFingerPrintFailures  = 5
+	maxIPFailures           = 20
+	maxTimeCodeFailures     = 3
+	signInLockBase          = 30 * time.Second
+	signInLockMax           = 15 * time.Minute
+	attemptsKeyFingerPrint  = "fp."
+	attemptsKeyIP           = "ip."
+	attemptsKeyTimeCode     = "code."
+	opAttemptsServicePrefix = opAuthServPrefix + "attempts."
+)
+
+var ErrTooManyAttempts = errors.New("too many sign in attempts")
+
+// TooManyAttemptsError is returned while sign in is locked for the fingerprint or ip.
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *TooManyAttemptsError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrTooManyAttempts, e.RetryAfter)
+}
+
+func (e *TooManyAttemptsError) Is(target error) bool {
+	return target == ErrTooManyAttempts
+}
+
+// checkSignInLock returns TooManyAttemptsError if the fingerprint or ip is locked out.
+func (s *AuthService) checkSignInLock(ctx context.Context, fingerPrint, ip string) error {
+	const op = opAttemptsServicePrefix + "checkSignInLock"
+
+	retryAfter, err := s.redis.LockTTL(ctx, attemptsKeyFingerPrint+fingerPrint, attemptsKeyIP+ip)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if retryAfter > 0 {
+		return &TooManyAttemptsError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// registerSignInFailure counts a wrong time code guess. Crossing a limit locks the fingerprint or ip
+// with a backoff that doubles with every further failure, and invalidates the reg session the guesses
+// were aimed at. timeCode is set when the code exists but belongs to another fingerprint.
+func (s *AuthService) registerSignInFailure(ctx context.Context, fingerPrint, ip, timeCode string) error {
+	const op = opAttemptsServicePrefix + "registerSignInFailure"
+
+	fpKey := attemptsKeyFingerPrint + fingerPrint
+	failures, err := s.redis.RegisterFailure(ctx, fpKey, signInFailuresWindow)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if failures >= maxFingerPrintFailures {
+		if err := s.redis.Lock(ctx, fpKey, signInBackoff(failures-maxFingerPrintFailures)); err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+		if err := s.redis.DeleteRegSessionByFingerPrint(ctx, fingerPrint); err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+		logger.FromContext(ctx).Warn(op,
+			zap.Error(ErrTooManyAttempts),
+			zap.String("finger_print", fingerPrint),
+			zap.Int64("failures", failures),
+		)
+	}
+
+	ipKey := attemptsKeyIP + ip
+	failures, err = s.redis.RegisterFailure(ctx, ipKey, signInFailuresWindow)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if failures >= maxIPFailures {
+		if err := s.redis.Lock(ctx, ipKey, signInBackoff(failures-maxIPFailures)); err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+		logger.FromContext(ctx).Warn(op,
+			zap.Error(ErrTooManyAttempts),
+			zap.String("ip", ip),
+			zap.Int64("failures", failures),
+		)
+	}
+
+	if timeCode == "" {
+		return nil
+	}
+
+	codeKey := attemptsKeyTimeCode + timeCode
+	failures, err = s.redis.RegisterFailure(ctx, codeKey, signInFailuresWindow)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if failures >= maxTimeCodeFailures {
+		if err := s.redis.DeleteRegSession(ctx, timeCode); err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+		if err := s.redis.ResetFailures(ctx, codeKey); err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// resetSignInFailures forgets the failures of the fingerprint after a successful sign in. The ip counter
+// is left to expire, otherwise confirming an own code now and then would reset the limit of the guesses
+// made from the same ip.
+func (s *AuthService) resetSignInFailures(ctx context.Context, fingerPrint string) error {
+	const op = opAttemptsServicePrefix + "resetSignInFailures"
+
+	if err := s.redis.ResetFailures(ctx, attemptsKeyFingerPrint+fingerPrint); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+func signInBackoff(excess int64) time.Duration {
+	lock := signInLockBase
+	for i := int64(0); i < excess && lock < signInLockMax; i++ {
+		lock *= 2
+	}
+	return min(lock, signInLockMax)
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/storage"
+)
+
+func TestSignInBackoff(t *testing.T) {
+	tests := []struct {
+		excess int64
+		want   time.Duration
+	}{
+		{excess: 0, want: signInLockBase},
+		{excess: 1, want: 2 * signInLockBase},
+		{excess: 3, want: 8 * signInLockBase},
+		{excess: 4, want: 8 * time.Minute},
+		{excess: 5, want: signInLockMax},
+		{excess: 1000, want: signInLockMax},
+	}
+	for _, tt := range tests {
+		if got := signInBackoff(tt.excess); got != tt.want {
+			t.Errorf("signInBackoff(%d) = %s, want %s", tt.excess, got, tt.want)
+		}
+	}
+}
+
+// fakeAttempts serves one reg session and records the failure counters reset by the service, the other
+// redis methods are not used by a sign in.
+type fakeAttempts struct {
+	storage.Redis
+	session models.RegSession
+	reset   []string
+}
+
+func (f *fakeAttempts) LockTTL(context.Context, ...string) (time.Duration, error) {
+	return 0, nil
+}
+
+func (f *fakeAttempts) GetRegSession(context.Context, string) (string, error) {
+	val, err := json.Marshal(f.session)
+	return string(val), err
+}
+
+func (f *fakeAttempts) ResetFailures(_ context.Context, keys ...string) error {
+	f.reset = append(f.reset, keys...)
+	return nil
+}
+
+func TestSignInKeepsIPFailures(t *testing.T) {
+	redis := &fakeAttempts{session: models.RegSession{FingerPrint: "fp", UserID: "100", IsConfirmed: true}}
+	s := &AuthService{redis: redis}
+
+	userID, err := s.GetRegSession(context.Background(), "fp", "123456", "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userID != "100" {
+		t.Errorf("got user %q, want 100", userID)
+	}
+	if want := []string{attemptsKeyFingerPrint + "fp"}; !reflect.DeepEqual(redis.reset, want) {
+		t.Errorf("reset %v, want %v", redis.reset, want)
+	}
+}
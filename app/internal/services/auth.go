@@ -11,15 +11,19 @@ import (
 
 	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
 	jwtmanager "github.com/UdinSemen/moscow-events-backend/internal/jwt-manager"
+	"github.com/UdinSemen/moscow-events-backend/internal/logger"
 	"github.com/UdinSemen/moscow-events-backend/internal/storage"
 	storagePg "github.com/UdinSemen/moscow-events-backend/internal/storage/postgres"
-	"github.com/UdinSemen/moscow-events-backend/pkg/utils"
+	storageRedis "github.com/UdinSemen/moscow-events-backend/internal/storage/redis"
+	"github.com/UdinSemen/moscow-events-backend/internal/tracing"
+	"github.com/UdinSemen/moscow-events-backend/pkg/random"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
 const (
 	timeCodeLen      = 6
+	timeCodeAttempts = 5
 	opAuthServPrefix = "service."
 )
 
@@ -30,45 +34,81 @@ var (
 	ErrInvalidUserID        = errors.New("invalid userID")
 	ErrRefreshTokenExp      = errors.New("refresh token expired")
 	ErrDifferentFingerPrint = errors.New("different fingerprint")
+	ErrNoSessionToRevoke    = errors.New("neither session id nor refresh token given")
+	ErrRefreshTokenReused   = errors.New("refresh token reused")
+	ErrNoFreeTimeCode       = errors.New("no free time code found")
+	ErrRegSessionTimeout    = errors.New("reg session wasn't confirmed in time")
 )
 
 type AuthService struct {
 	refreshTokenTTL time.Duration
+	accessTokenTTL  time.Duration
 	redis           storage.Redis
 	postgres        storage.PgStorage
 	jwtManager      jwtmanager.TokenManager
 }
 
-func NewAuth(redis storage.Redis, postgres storage.PgStorage, refreshTTL time.Duration, jwtManager jwtmanager.TokenManager) *AuthService {
+func NewAuth(redis storage.Redis,
+	postgres storage.PgStorage,
+	refreshTTL,
+	accessTTL time.Duration,
+	jwtManager jwtmanager.TokenManager) *AuthService {
 	zap.S().Infow("tokenTTL",
 		"refresh", refreshTTL)
 	return &AuthService{
 		redis:           redis,
 		postgres:        postgres,
 		refreshTokenTTL: refreshTTL,
+		accessTokenTTL:  accessTTL,
 		jwtManager:      jwtManager,
 	}
 }
 
+// CreateRegSession stores a reg session under a fresh time code. Codes are short, so a code that is
+// still in use by another reg session is skipped and a new one is drawn.
 func (s *AuthService) CreateRegSession(ctx context.Context, fingerPrint string) (string, error) {
-	timeCode := utils.GenTimeCode(timeCodeLen)
-	return timeCode, s.redis.CreateRegSession(ctx, fingerPrint, timeCode)
+	const op = opAuthServPrefix + "CreateRegSession"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	for i := 0; i < timeCodeAttempts; i++ {
+		timeCode, err := random.Digits(timeCodeLen)
+		if err != nil {
+			return "", fmt.Errorf("%s:%w", op, err)
+		}
+
+		err = s.redis.CreateRegSession(ctx, fingerPrint, timeCode)
+		if err == nil {
+			return timeCode, nil
+		}
+		if !errors.Is(err, storageRedis.ErrTimeCodeExists) {
+			return "", fmt.Errorf("%s:%w", op, err)
+		}
+	}
+
+	return "", fmt.Errorf("%s:%w", op, ErrNoFreeTimeCode)
 }
 
-func (s *AuthService) GetRegSession(ctx context.Context, fingerPrint, timeCode string) (string, error) {
+func (s *AuthService) GetRegSession(ctx context.Context, fingerPrint, timeCode, ip string) (string, error) {
 	const op = opAuthServPrefix + "GetRegSession"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := s.checkSignInLock(ctx, fingerPrint, ip); err != nil {
+		return "", err
+	}
 
 	val, err := s.redis.GetRegSession(ctx, timeCode)
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
-			return "", ErrNoRegSession
+			return "", s.signInFailed(ctx, fingerPrint, ip, "", ErrNoRegSession)
 		} else {
 			return "", fmt.Errorf("%s:%w", op, err)
 		}
 	}
 
 	if val == "" {
-		return "", ErrNoRegSession
+		return "", s.signInFailed(ctx, fingerPrint, ip, "", ErrNoRegSession)
 	}
 
 	var session models.RegSession
@@ -77,7 +117,7 @@ func (s *AuthService) GetRegSession(ctx context.Context, fingerPrint, timeCode s
 	}
 
 	if session.FingerPrint != fingerPrint {
-		return "", ErrInvalidFingerPrint
+		return "", s.signInFailed(ctx, fingerPrint, ip, timeCode, ErrInvalidFingerPrint)
 	}
 
 	if !session.IsConfirmed {
@@ -89,16 +129,93 @@ func (s *AuthService) GetRegSession(ctx context.Context, fingerPrint, timeCode s
 		return "", ErrInvalidUserID
 	}
 
+	if err := s.resetSignInFailures(ctx, fingerPrint); err != nil {
+		logger.FromContext(ctx).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
+	}
+
 	return userTgId, nil
 }
 
-func (s *AuthService) InitSession(ctx context.Context, userID, refreshToken, ip, fingerprint string) error {
+// WaitRegSession blocks until the reg session of the time code is confirmed and returns the telegram
+// user id like GetRegSession. It waits at most for the rest of the time code lifetime, and returns
+// ErrRegSessionTimeout when it's over or the context error when ctx is cancelled earlier.
+func (s *AuthService) WaitRegSession(ctx context.Context, fingerPrint, timeCode, ip string) (string, error) {
+	const op = opAuthServPrefix + "WaitRegSession"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	updates, unsubscribe, err := s.redis.SubscribeRegSession(ctx, timeCode)
+	if err != nil {
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+	defer func() {
+		if err := unsubscribe(); err != nil {
+			logger.FromContext(ctx).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
+		}
+	}()
+
+	ttl, err := s.redis.RegSessionTTL(ctx, timeCode)
+	if err != nil {
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+	// a missing key is reported by GetRegSession below, it also counts the failed attempt. A key without
+	// an expiry must not hold the wait forever, it is bounded by the time code lifetime then
+	if ttl <= 0 || ttl > storageRedis.TimeCodeTTL {
+		ttl = storageRedis.TimeCodeTTL
+	}
+	ctx, cancel := context.WithTimeout(ctx, ttl)
+	defer cancel()
+
+	for {
+		userTgId, err := s.GetRegSession(ctx, fingerPrint, timeCode, ip)
+		if err == nil {
+			return userTgId, nil
+		}
+		if !errors.Is(err, ErrSessionNotConfirmed) {
+			return "", err
+		}
+
+		select {
+		case <-updates:
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return "", ErrRegSessionTimeout
+			}
+			return "", fmt.Errorf("%s:%w", op, ctx.Err())
+		}
+	}
+}
+
+// signInFailed registers the failed attempt and returns the reason of the failure.
+func (s *AuthService) signInFailed(ctx context.Context, fingerPrint, ip, timeCode string, reason error) error {
+	const op = opAuthServPrefix + "signInFailed"
+
+	if err := s.registerSignInFailure(ctx, fingerPrint, ip, timeCode); err != nil {
+		return fmt.Errorf("%s:%w", op, errors.Join(reason, err))
+	}
+	return reason
+}
+
+func (s *AuthService) NewSessionID(_ context.Context) (string, error) {
+	const op = opAuthServPrefix + "NewSessionID"
+
+	sessionID, err := random.UUID()
+	if err != nil {
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+	return sessionID, nil
+}
+
+func (s *AuthService) InitSession(ctx context.Context, sessionID, userID, refreshToken, ip, fingerprint string) error {
 	const op = opAuthServPrefix + "InitUser"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	refreshTokenExp := time.Now().Add(s.refreshTokenTTL)
 	return s.postgres.InitSession(ctx,
+		sessionID,
 		userID,
-		refreshToken,
+		s.jwtManager.HashRefreshToken(refreshToken),
 		ip,
 		fingerprint,
 		refreshTokenExp)
@@ -106,6 +223,8 @@ func (s *AuthService) InitSession(ctx context.Context, userID, refreshToken, ip,
 
 func (s *AuthService) GetUserDTOByTg(ctx context.Context, userTgId string) (models.UserDTO, error) {
 	const op = opAuthServPrefix + "GetUserDTO"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	id, err := strconv.ParseInt(userTgId, 10, 64)
 	if err != nil {
@@ -116,10 +235,10 @@ func (s *AuthService) GetUserDTOByTg(ctx context.Context, userTgId string) (mode
 	}, storagePg.TypeTgID)
 }
 
-func (s *AuthService) GenerateTokens(_ context.Context, userID, role string) (string, string, error) {
+func (s *AuthService) GenerateTokens(_ context.Context, userID, role, sessionID string) (string, string, error) {
 	const op = opAuthServPrefix + "GenerateTokens"
 
-	accessToken, err := s.jwtManager.GenerateToken(userID, role)
+	accessToken, err := s.jwtManager.GenerateToken(userID, role, sessionID)
 	if err != nil {
 		return "", "", fmt.Errorf("%s:%w", op, err)
 	}
@@ -132,18 +251,25 @@ func (s *AuthService) GenerateTokens(_ context.Context, userID, role string) (st
 	return accessToken, refreshToken, nil
 }
 
-func (s *AuthService) RefreshToken(ctx context.Context, refreshToken, fingerprint string) (string, string, error) {
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken, fingerprint, ip string) (string, string, error) {
 	const op = opAuthServPrefix + "RefreshToken"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
-	session, err := s.postgres.GetSession(ctx, refreshToken)
+	session, err := s.postgres.GetSession(ctx, s.jwtManager.HashRefreshToken(refreshToken))
 	if err != nil {
+		if errors.Is(err, storagePg.ErrNoRows) {
+			if reuseErr := s.detectRefreshReuse(ctx, refreshToken, fingerprint, ip); reuseErr != nil {
+				return "", "", fmt.Errorf("%s:%w", op, reuseErr)
+			}
+		}
 		return "", "", fmt.Errorf("%s:%w", op, err)
 	}
 
-	zap.S().Debug(session)
-	zap.S().Debug(session.ExpiredAt)
-	zap.S().Debug(session.ExpiredAt.Before(time.Now()))
-	zap.S().Debug(time.Now())
+	logger.FromContext(ctx).Sugar().Debug(session)
+	logger.FromContext(ctx).Sugar().Debug(session.ExpiredAt)
+	logger.FromContext(ctx).Sugar().Debug(session.ExpiredAt.Before(time.Now()))
+	logger.FromContext(ctx).Sugar().Debug(time.Now())
 	if session.ExpiredAt.Before(time.Now()) {
 		return "", "", fmt.Errorf("%s:%w", op, ErrRefreshTokenExp)
 	}
@@ -161,12 +287,155 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken, fingerprin
 		return "", "", fmt.Errorf("%s:%w", op, err)
 	}
 
-	return s.GenerateTokens(ctx, userDTO.Uuid, userDTO.Role)
+	return s.GenerateTokens(ctx, userDTO.Uuid, userDTO.Role, session.ID)
+}
+
+// detectRefreshReuse checks whether an unknown refresh token is an already rotated one. A replay means
+// the token leaked, so the whole family, i.e. the session and every token rotated from it, is revoked.
+// It returns ErrRefreshTokenReused if the family was revoked and nil if the token is simply unknown.
+func (s *AuthService) detectRefreshReuse(ctx context.Context, refreshToken, fingerprint, ip string) error {
+	const op = opAuthServPrefix + "detectRefreshReuse"
+
+	session, err := s.postgres.GetRotatedSession(ctx, s.jwtManager.HashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, storagePg.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	logger.FromContext(ctx).Warn(op,
+		zap.Error(ErrRefreshTokenReused),
+		zap.String("user_id", session.UserID),
+		zap.String("session_id", session.ID),
+		zap.String("ip", ip),
+	)
+
+	if err := s.RevokeSession(ctx, session.UserID, session.ID); err != nil && !errors.Is(err, storagePg.ErrNoRows) {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	if err := s.postgres.AddAuditEvent(ctx, models.AuditEvent{
+		UserID:      session.UserID,
+		SessionID:   session.ID,
+		Event:       models.AuditRefreshTokenReuse,
+		IP:          ip,
+		FingerPrint: fingerprint,
+		Details:     "rotated refresh token replayed, session family revoked",
+	}); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return ErrRefreshTokenReused
 }
 
 func (s *AuthService) RefreshSession(ctx context.Context, refreshTokenOld, refreshTokenNew, ip string) error {
 	const op = opAuthServPrefix + "RefreshSession"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
 	expireAt := time.Now().Add(s.refreshTokenTTL)
-	return s.postgres.RefreshSession(ctx, refreshTokenOld, refreshTokenNew, ip, expireAt)
+	return s.postgres.RefreshSession(ctx,
+		s.jwtManager.HashRefreshToken(refreshTokenOld),
+		s.jwtManager.HashRefreshToken(refreshTokenNew),
+		ip,
+		expireAt)
+}
+
+// Logout revokes the session the access token was issued for. Tokens issued without a session id
+// fall back to the refresh token of the session.
+func (s *AuthService) Logout(ctx context.Context, user models.UserDTO, refreshToken string) error {
+	const op = opAuthServPrefix + "Logout"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if user.SessionID != "" {
+		return s.RevokeSession(ctx, user.Uuid, user.SessionID)
+	}
+	if refreshToken == "" {
+		return fmt.Errorf("%s:%w", op, ErrNoSessionToRevoke)
+	}
+
+	sessionID, err := s.postgres.DeleteSessionByToken(ctx, user.Uuid, s.jwtManager.HashRefreshToken(refreshToken))
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if err := s.redis.RevokeSessions(ctx, s.accessTokenTTL, sessionID); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+func (s *AuthService) LogoutAll(ctx context.Context, userID string) error {
+	const op = opAuthServPrefix + "LogoutAll"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	sessionIDs, err := s.postgres.DeleteUserSessions(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if err := s.redis.RevokeSessions(ctx, s.accessTokenTTL, sessionIDs...); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	const op = opAuthServPrefix + "RevokeSession"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := s.postgres.DeleteSession(ctx, userID, sessionID); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if err := s.redis.RevokeSessions(ctx, s.accessTokenTTL, sessionID); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+func (s *AuthService) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	const op = opAuthServPrefix + "IsSessionRevoked"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if sessionID == "" {
+		return false, nil
+	}
+
+	revoked, err := s.redis.IsSessionRevoked(ctx, sessionID)
+	if err != nil {
+		return false, fmt.Errorf("%s:%w", op, err)
+	}
+	return revoked, nil
+}
+
+func (s *AuthService) GetActiveSessions(ctx context.Context, user models.UserDTO) ([]models.ActiveSession, error) {
+	const op = opAuthServPrefix + "GetActiveSessions"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	sessions, err := s.postgres.GetUserSessions(ctx, user.Uuid)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	active := make([]models.ActiveSession, 0, len(sessions))
+	for _, session := range sessions {
+		active = append(active, models.ActiveSession{
+			ID:              session.ID,
+			IP:              session.IP,
+			Device:          deviceLabel(session.FingerPrint),
+			CreatedAt:       session.CreatedAt,
+			LastRefreshedAt: session.UpdatedAt,
+			ExpiredAt:       session.ExpiredAt,
+			Current:         session.ID == user.SessionID,
+		})
+	}
+
+	return active, nil
 }
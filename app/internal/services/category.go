@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/logger"
+	"github.com/UdinSemen/moscow-events-backend/internal/storage"
+	"github.com/UdinSemen/moscow-events-backend/internal/tracing"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	categoryServiceOpPrefix = "services.category."
+	categoriesCacheTTL      = 10 * time.Minute
+	categoriesCachePrefix   = "categories."
+	categoriesCacheKey      = categoriesCachePrefix + "%s.%s"
+	dateLayout              = "2006-01-02"
+)
+
+type CategoryService struct {
+	postgres storage.PgStorage
+	redis    storage.Redis
+}
+
+func NewCategoryService(postgres storage.PgStorage, redis storage.Redis) *CategoryService {
+	return &CategoryService{
+		postgres: postgres,
+		redis:    redis,
+	}
+}
+
+// GetCategories returns the category catalogue with the counts of events in the window. Results are
+// cached per window, a cache failure only costs a database query.
+func (s *CategoryService) GetCategories(ctx context.Context, dateFrom, dateTo time.Time) ([]models.Category, error) {
+	const op = categoryServiceOpPrefix + "GetCategories"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if dateFrom.After(dateTo) {
+		return nil, fmt.Errorf("%s:%w", op, ErrInvalidRange)
+	}
+
+	key := fmt.Sprintf(categoriesCacheKey, dateFrom.Format(dateLayout), dateTo.Format(dateLayout))
+	cached, err := s.redis.GetCache(ctx, key)
+	switch {
+	case err == nil:
+		var categories []models.Category
+		if err := json.Unmarshal(cached, &categories); err == nil {
+			return categories, nil
+		}
+		logger.FromContext(ctx).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
+	case !errors.Is(err, redis.Nil):
+		logger.FromContext(ctx).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
+	}
+
+	categories, err := s.postgres.GetCategories(ctx, dateFrom, dateTo)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	if val, err := json.Marshal(categories); err != nil {
+		logger.FromContext(ctx).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
+	} else if err := s.redis.SetCache(ctx, key, val, categoriesCacheTTL); err != nil {
+		logger.FromContext(ctx).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
+	}
+
+	return categories, nil
+}
+
+// invalidateCategories drops the cached counts after events change. A failure is only logged, the cache
+// expires on its own.
+func invalidateCategories(ctx context.Context, redis storage.Redis) {
+	const op = categoryServiceOpPrefix + "invalidateCategories"
+
+	if err := redis.DeleteCache(ctx, categoriesCachePrefix); err != nil {
+		logger.FromContext(ctx).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
+	}
+}
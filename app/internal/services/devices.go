@@ -0,0 +1,68 @@
+package services
+
+import (
+	"strings"
+)
+
+const (
+	unknownDevice        = "Unknown device"
+	deviceFingerprintLen = 8
+)
+
+var (
+	// order matters: iOS and Android user agents also mention Linux or Mac OS
+	devicePlatforms = []struct{ marker, name string }{
+		{"iphone", "iPhone"},
+		{"ipad", "iPad"},
+		{"android", "Android"},
+		{"windows", "Windows"},
+		{"mac os", "macOS"},
+		{"macintosh", "macOS"},
+		{"cros", "ChromeOS"},
+		{"linux", "Linux"},
+	}
+	// order matters: Edge and Opera user agents also mention Chrome, Chrome mentions Safari
+	deviceBrowsers = []struct{ marker, name string }{
+		{"edg/", "Edge"},
+		{"opr/", "Opera"},
+		{"yabrowser", "Yandex Browser"},
+		{"firefox", "Firefox"},
+		{"chrome", "Chrome"},
+		{"safari", "Safari"},
+	}
+)
+
+// deviceLabel makes a human-readable device name from a session fingerprint. Clients that send a user
+// agent get platform and browser, for opaque fingerprints only a short prefix is shown.
+func deviceLabel(fingerprint string) string {
+	lower := strings.ToLower(fingerprint)
+
+	var platform, browser string
+	for _, p := range devicePlatforms {
+		if strings.Contains(lower, p.marker) {
+			platform = p.name
+			break
+		}
+	}
+	for _, b := range deviceBrowsers {
+		if strings.Contains(lower, b.marker) {
+			browser = b.name
+			break
+		}
+	}
+
+	switch {
+	case platform != "" && browser != "":
+		return browser + " on " + platform
+	case platform != "":
+		return platform
+	case browser != "":
+		return browser
+	case len(fingerprint) > deviceFingerprintLen:
+		return unknownDevice + " (" + fingerprint[:deviceFingerprintLen] + ")"
+	case fingerprint != "":
+		return unknownDevice + " (" + fingerprint + ")"
+	default:
+		return unknownDevice
+	}
+}
@@ -1,30 +1,341 @@
 package services
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
 	"github.com/UdinSemen/moscow-events-backend/internal/storage"
+	"github.com/UdinSemen/moscow-events-backend/internal/tracing"
+	"github.com/UdinSemen/moscow-events-backend/pkg/price"
 	"golang.org/x/net/context"
 )
 
-const eventServiceOpPrefix = "services.event."
+const (
+	eventServiceOpPrefix = "services.event."
+	maxSearchQueryLen    = 256
+	maxFilterValues      = 50
+
+	defaultEventsPageLimit = 50
+	maxEventsPageLimit     = 200
+)
+
+var (
+	ErrInvalidEvent   = errors.New("src, category, label and at least one date are required")
+	ErrDuplicateDates = errors.New("duplicate event dates")
+	ErrEmptyQuery     = errors.New("empty search query")
+	ErrInvalidRange   = errors.New("date_from is after date_to")
+	ErrInvalidCursor  = errors.New("invalid cursor")
+
+	ErrInvalidFilter     = errors.New("too many categories or sources")
+	ErrInvalidPriceRange = errors.New("negative price or price_min is greater than price_max")
+)
+
+// cursorIdRegexp matches the date id of a cursor, storage casts it to uuid.
+var cursorIdRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
 
 type EventService struct {
 	postgres storage.PgStorage
+	redis    storage.Redis
 }
 
-func NewEventService(postgres storage.PgStorage) *EventService {
-	return &EventService{postgres: postgres}
+func NewEventService(postgres storage.PgStorage, redis storage.Redis) *EventService {
+	return &EventService{
+		postgres: postgres,
+		redis:    redis,
+	}
 }
 
-func (s *EventService) GetEvents(ctx context.Context, userID, category string, date []time.Time) ([]models.Event, error) {
-	const op = opAuthServPrefix + "GetEvents"
+func (s *EventService) GetEvents(ctx context.Context,
+	userID string,
+	filter models.EventFilter,
+	page models.EventPage) ([]models.Event, string, error) {
+	const op = eventServiceOpPrefix + "GetEvents"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	filter, err := normalizeEventFilter(filter)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s:%w", op, err)
+	}
+
+	if page.Sort == "" {
+		page.Sort = models.SortDate
+	}
+	page.Limit = eventsPageLimit(page.Limit)
+
+	var after *models.EventCursor
+	if page.Cursor != "" {
+		cursor, err := decodeEventCursor(page.Cursor)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s:%w", op, err)
+		}
+		if cursor.Sort != page.Sort {
+			return nil, "", fmt.Errorf("%s:%w", op, ErrInvalidCursor)
+		}
+		after = &cursor
+	}
+
+	events, next, err := s.postgres.GetEvents(ctx, userID, filter, page.Sort, page.Limit, after)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s:%w", op, err)
+	}
+	if next == nil {
+		return events, "", nil
+	}
+
+	nextCursor, err := encodeEventCursor(*next)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s:%w", op, err)
+	}
+	return events, nextCursor, nil
+}
+
+// eventsPageLimit caps the page size. Clients that don't paginate get the first page, its next_cursor
+// tells them there is more.
+func eventsPageLimit(limit int) int {
+	switch {
+	case limit <= 0:
+		return defaultEventsPageLimit
+	case limit > maxEventsPageLimit:
+		return maxEventsPageLimit
+	}
+	return limit
+}
+
+// normalizeEventFilter drops blank and repeated categories and sources, trims the text query and checks
+// that the ranges are not inverted.
+func normalizeEventFilter(filter models.EventFilter) (models.EventFilter, error) {
+	filter.Categories = compactStrings(filter.Categories)
+	filter.Sources = compactStrings(filter.Sources)
+	if len(filter.Categories) > maxFilterValues || len(filter.Sources) > maxFilterValues {
+		return models.EventFilter{}, ErrInvalidFilter
+	}
+
+	if filter.DateFrom != nil && filter.DateTo != nil && filter.DateFrom.After(*filter.DateTo) {
+		return models.EventFilter{}, ErrInvalidRange
+	}
+	if (filter.PriceMin != nil && *filter.PriceMin < 0) || (filter.PriceMax != nil && *filter.PriceMax < 0) {
+		return models.EventFilter{}, ErrInvalidPriceRange
+	}
+	if filter.PriceMin != nil && filter.PriceMax != nil && *filter.PriceMin > *filter.PriceMax {
+		return models.EventFilter{}, ErrInvalidPriceRange
+	}
+
+	filter.Query = strings.TrimSpace(filter.Query)
+	if len([]rune(filter.Query)) > maxSearchQueryLen {
+		filter.Query = string([]rune(filter.Query)[:maxSearchQueryLen])
+	}
+
+	return filter, nil
+}
+
+func compactStrings(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v != "" && !slices.Contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func encodeEventCursor(cursor models.EventCursor) (string, error) {
+	b, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeEventCursor(raw string) (models.EventCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return models.EventCursor{}, ErrInvalidCursor
+	}
+
+	var cursor models.EventCursor
+	if err := json.Unmarshal(b, &cursor); err != nil || !cursorIdRegexp.MatchString(cursor.DateId) {
+		return models.EventCursor{}, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+func (s *EventService) SearchEvents(ctx context.Context, userID string, search models.EventSearch) ([]models.EventSearchResult, error) {
+	const op = eventServiceOpPrefix + "SearchEvents"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	search.Query = strings.TrimSpace(search.Query)
+	if search.Query == "" {
+		return nil, fmt.Errorf("%s:%w", op, ErrEmptyQuery)
+	}
+	if len([]rune(search.Query)) > maxSearchQueryLen {
+		search.Query = string([]rune(search.Query)[:maxSearchQueryLen])
+	}
+	if search.DateFrom != nil && search.DateTo != nil && search.DateFrom.After(*search.DateTo) {
+		return nil, fmt.Errorf("%s:%w", op, ErrInvalidRange)
+	}
 
-	events, err := s.postgres.GetEvents(ctx, userID, category, date)
+	events, err := s.postgres.SearchEvents(ctx, userID, search)
 	if err != nil {
 		return nil, fmt.Errorf("%s:%w", op, err)
 	}
 	return events, nil
 }
+
+func (s *EventService) GetEventByID(ctx context.Context, eventID string) (models.EventDetails, error) {
+	const op = eventServiceOpPrefix + "GetEventByID"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	event, err := s.postgres.GetEventByID(ctx, eventID)
+	if err != nil {
+		return models.EventDetails{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return event, nil
+}
+
+func (s *EventService) GetEvent(ctx context.Context, userID, eventID string) (models.EventDetails, error) {
+	const op = eventServiceOpPrefix + "GetEvent"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	event, err := s.postgres.GetEvent(ctx, userID, eventID)
+	if err != nil {
+		return models.EventDetails{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return event, nil
+}
+
+func (s *EventService) CreateEvent(ctx context.Context, input models.EventInput) (string, error) {
+	const op = eventServiceOpPrefix + "CreateEvent"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	input, err := normalizeEventInput(input)
+	if err != nil {
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+
+	eventID, err := s.postgres.CreateEvent(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+	invalidateCategories(ctx, s.redis)
+	return eventID, nil
+}
+
+func (s *EventService) UpdateEvent(ctx context.Context, eventID string, input models.EventInput) error {
+	const op = eventServiceOpPrefix + "UpdateEvent"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	input, err := normalizeEventInput(input)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	if err := s.postgres.UpdateEvent(ctx, eventID, input); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	invalidateCategories(ctx, s.redis)
+	return nil
+}
+
+func (s *EventService) DeleteEvent(ctx context.Context, eventID string) error {
+	const op = eventServiceOpPrefix + "DeleteEvent"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := s.postgres.DeleteEvent(ctx, eventID); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	invalidateCategories(ctx, s.redis)
+	return nil
+}
+
+// ParsePrice normalizes a free text price of an event.
+func ParsePrice(raw string) models.EventPrice {
+	p := price.Parse(raw)
+	return models.EventPrice{
+		PriceMin:      p.Min,
+		PriceMax:      p.Max,
+		PriceCurrency: p.Currency,
+		IsFree:        p.IsFree,
+	}
+}
+
+// normalizeEventInput trims text fields, parses the price, truncates dates to a calendar day and rejects
+// duplicates.
+func normalizeEventInput(input models.EventInput) (models.EventInput, error) {
+	input.Src = strings.TrimSpace(input.Src)
+	input.Category = strings.TrimSpace(input.Category)
+	input.Label = strings.TrimSpace(input.Label)
+	input.Description = strings.TrimSpace(input.Description)
+	input.Price = strings.TrimSpace(input.Price)
+	input.EventPrice = ParsePrice(input.Price)
+	input.Url = strings.TrimSpace(input.Url)
+	input.UrlImg = strings.TrimSpace(input.UrlImg)
+	input.UrlBuy = strings.TrimSpace(input.UrlBuy)
+
+	if input.Src == "" || input.Category == "" || input.Label == "" {
+		return models.EventInput{}, ErrInvalidEvent
+	}
+	if len(input.Dates) == 0 {
+		return models.EventInput{}, ErrInvalidEvent
+	}
+
+	dates := make([]time.Time, 0, len(input.Dates))
+	for _, date := range input.Dates {
+		day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		if slices.ContainsFunc(dates, day.Equal) {
+			return models.EventInput{}, ErrDuplicateDates
+		}
+		dates = append(dates, day)
+	}
+	input.Dates = dates
+
+	return input, nil
+}
+
+func (s *EventService) AddFavourite(ctx context.Context, userID, eventID, dateID string) error {
+	const op = eventServiceOpPrefix + "AddFavourite"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := s.postgres.AddFavourite(ctx, userID, eventID, dateID); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+func (s *EventService) RemoveFavourite(ctx context.Context, userID, eventID, dateID string) error {
+	const op = eventServiceOpPrefix + "RemoveFavourite"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := s.postgres.RemoveFavourite(ctx, userID, eventID, dateID); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+func (s *EventService) GetFavourites(ctx context.Context, userID string, limit, offset int) ([]models.Event, int, error) {
+	const op = eventServiceOpPrefix + "GetFavourites"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	events, total, err := s.postgres.GetFavourites(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%w", op, err)
+	}
+	return events, total, nil
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+)
+
+func TestNormalizeEventFilter(t *testing.T) {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	negative, low, high := -1.0, 100.0, 2000.0
+	tooMany := make([]string, maxFilterValues+1)
+	for i := range tooMany {
+		tooMany[i] = "category-" + strconv.Itoa(i)
+	}
+
+	tests := []struct {
+		name    string
+		filter  models.EventFilter
+		want    models.EventFilter
+		wantErr error
+	}{
+		{
+			name: "compacts values and trims the query",
+			filter: models.EventFilter{
+				Categories: []string{" concerts", "concerts", "", "theater "},
+				Sources:    []string{"kudago", " "},
+				DateFrom:   &from,
+				DateTo:     &to,
+				Query:      "  джаз  ",
+			},
+			want: models.EventFilter{
+				Categories: []string{"concerts", "theater"},
+				Sources:    []string{"kudago"},
+				DateFrom:   &from,
+				DateTo:     &to,
+				Query:      "джаз",
+			},
+		},
+		{
+			name:   "no dates",
+			filter: models.EventFilter{},
+			want:   models.EventFilter{Categories: []string{}, Sources: []string{}},
+		},
+		{
+			name:   "only date_from",
+			filter: models.EventFilter{DateFrom: &from},
+			want:   models.EventFilter{Categories: []string{}, Sources: []string{}, DateFrom: &from},
+		},
+		{
+			name:    "inverted dates",
+			filter:  models.EventFilter{DateFrom: &to, DateTo: &from},
+			wantErr: ErrInvalidRange,
+		},
+		{
+			name:    "negative price",
+			filter:  models.EventFilter{DateFrom: &from, DateTo: &to, PriceMin: &negative},
+			wantErr: ErrInvalidPriceRange,
+		},
+		{
+			name:    "inverted prices",
+			filter:  models.EventFilter{DateFrom: &from, DateTo: &to, PriceMin: &high, PriceMax: &low},
+			wantErr: ErrInvalidPriceRange,
+		},
+		{
+			name: "too many categories",
+			filter: models.EventFilter{
+				DateFrom:   &from,
+				DateTo:     &to,
+				Categories: tooMany,
+			},
+			wantErr: ErrInvalidFilter,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeEventFilter(tt.filter)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventsPageLimit(t *testing.T) {
+	tests := []struct {
+		limit, want int
+	}{
+		{limit: 0, want: defaultEventsPageLimit},
+		{limit: -1, want: defaultEventsPageLimit},
+		{limit: 10, want: 10},
+		{limit: maxEventsPageLimit, want: maxEventsPageLimit},
+		{limit: maxEventsPageLimit + 1, want: maxEventsPageLimit},
+	}
+	for _, tt := range tests {
+		if got := eventsPageLimit(tt.limit); got != tt.want {
+			t.Errorf("eventsPageLimit(%d) = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestEventCursor(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	cursors := []models.EventCursor{
+		{Sort: models.SortDate, Date: date, DateId: "5f0c6d1e-8a51-4d43-9d57-4c7c1f0b6f2e"},
+		{Sort: models.SortPrice, Date: date, Price: 1500.5, DateId: "5f0c6d1e-8a51-4d43-9d57-4c7c1f0b6f2e"},
+		{Sort: models.SortRecent, Date: date, CreatedAt: date.Add(-time.Hour), DateId: "0b7e3c52-2a5f-4d1e-8f0a-6c3b9d2e1f40"},
+	}
+	for _, cursor := range cursors {
+		t.Run(cursor.Sort, func(t *testing.T) {
+			encoded, err := encodeEventCursor(cursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := decodeEventCursor(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(decoded, cursor) {
+				t.Errorf("got %+v, want %+v", decoded, cursor)
+			}
+		})
+	}
+
+	notUUID, err := encodeEventCursor(models.EventCursor{Sort: models.SortDate, Date: date, DateId: "x'; --"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, raw := range []string{"", "not base64!", "bm90IGpzb24", "e30", notUUID} {
+		if _, err := decodeEventCursor(raw); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("decodeEventCursor(%q) = %v, want %v", raw, err, ErrInvalidCursor)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/storage"
+	storagePg "github.com/UdinSemen/moscow-events-backend/internal/storage/postgres"
+)
+
+const healthCheckTimeout = 2 * time.Second
+
+var (
+	ErrShuttingDown       = errors.New("server is shutting down")
+	ErrMigrationsNotFound = errors.New("embedded migrations are unreadable")
+)
+
+type HealthService struct {
+	redis        storage.Redis
+	postgres     storage.PgStorage
+	shuttingDown atomic.Bool
+}
+
+func NewHealthService(redis storage.Redis, postgres storage.PgStorage) *HealthService {
+	return &HealthService{
+		redis:    redis,
+		postgres: postgres,
+	}
+}
+
+// SetShuttingDown fails the readiness from now on, so the instance is taken out of rotation before the
+// server stops.
+func (s *HealthService) SetShuttingDown() {
+	s.shuttingDown.Store(true)
+}
+
+// Ready runs the readiness checks concurrently, each one within its own timeout.
+func (s *HealthService) Ready(ctx context.Context) models.HealthReport {
+	checks := map[string]func(ctx context.Context) error{
+		"postgres":   s.postgres.Ping,
+		"redis":      s.redis.Ping,
+		"migrations": s.checkMigrations,
+		"shutdown":   s.checkShutdown,
+	}
+
+	report := models.HealthReport{
+		Status: models.HealthOK,
+		Checks: make(map[string]models.HealthCheck, len(checks)),
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(ctx context.Context) error) {
+			defer wg.Done()
+			result := runHealthCheck(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != models.HealthOK {
+				report.Status = models.HealthFail
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func runHealthCheck(ctx context.Context, check func(ctx context.Context) error) models.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := models.HealthCheck{
+		Status:     models.HealthOK,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = models.HealthFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// checkMigrations fails while the schema is behind the migrations embedded in the binary.
+func (s *HealthService) checkMigrations(ctx context.Context) error {
+	latest, err := storagePg.LatestMigration()
+	if err != nil {
+		return ErrMigrationsNotFound
+	}
+	version, err := s.postgres.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version < latest {
+		return fmt.Errorf("schema version %d is behind %d", version, latest)
+	}
+	return nil
+}
+
+func (s *HealthService) checkShutdown(context.Context) error {
+	if s.shuttingDown.Load() {
+		return ErrShuttingDown
+	}
+	return nil
+}
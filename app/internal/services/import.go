@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/tracing"
+	"golang.org/x/net/context"
+)
+
+const maxImportEvents = 5000
+
+var (
+	ErrEmptyImport      = errors.New("no valid events to import")
+	ErrImportTooLarge   = fmt.Errorf("more than %d events in the batch", maxImportEvents)
+	ErrIncompleteEvent  = errors.New("description, price and url_img are required")
+	ErrInvalidEventURL  = errors.New("url, url_img and url_buy must be absolute http urls")
+	ErrDuplicateLabel   = errors.New("duplicate label in the batch")
+	ErrInvalidImportKey = errors.New("src and category are required")
+)
+
+// ImportEvents validates the batch and replaces the actual events of its source and category with the
+// valid ones. Invalid events are reported and skipped, a batch without valid events changes nothing and
+// fails with ErrEmptyImport.
+func (s *EventService) ImportEvents(ctx context.Context, batch models.EventImport) (models.ImportReport, error) {
+	const op = eventServiceOpPrefix + "ImportEvents"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	src := strings.TrimSpace(batch.Src)
+	category := strings.TrimSpace(batch.Category)
+	if src == "" || category == "" {
+		return models.ImportReport{}, fmt.Errorf("%s:%w", op, ErrInvalidImportKey)
+	}
+	if len(batch.Events) > maxImportEvents {
+		return models.ImportReport{}, fmt.Errorf("%s:%w", op, ErrImportTooLarge)
+	}
+
+	report := models.ImportReport{Rejected: make([]models.ImportRejection, 0)}
+	events := make([]models.EventInput, 0, len(batch.Events))
+	labels := make(map[string]struct{}, len(batch.Events))
+	for i, event := range batch.Events {
+		input, err := validateImportEvent(models.EventInput{
+			Src:         src,
+			Category:    category,
+			Label:       event.Label,
+			Description: event.Description,
+			Price:       event.Price,
+			Url:         event.Url,
+			UrlImg:      event.UrlImg,
+			UrlBuy:      event.UrlBuy,
+			Dates:       event.Dates,
+		})
+		if _, ok := labels[input.Label]; err == nil && ok {
+			err = ErrDuplicateLabel
+		}
+		if err != nil {
+			report.Rejected = append(report.Rejected, models.ImportRejection{
+				Index:  i,
+				Label:  event.Label,
+				Reason: err.Error(),
+			})
+			continue
+		}
+		events = append(events, input)
+		labels[input.Label] = struct{}{}
+	}
+	if len(events) == 0 {
+		return report, fmt.Errorf("%s:%w", op, ErrEmptyImport)
+	}
+
+	idGroup, err := s.postgres.ImportEvents(ctx, src, category, events)
+	if err != nil {
+		return models.ImportReport{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	invalidateCategories(ctx, s.redis)
+
+	report.IdGroup = idGroup
+	report.Imported = len(events)
+	return report, nil
+}
+
+// validateImportEvent normalizes a scraped event and additionally requires everything the listings show.
+func validateImportEvent(input models.EventInput) (models.EventInput, error) {
+	input, err := normalizeEventInput(input)
+	if err != nil {
+		return models.EventInput{}, err
+	}
+	if input.Description == "" || input.Price == "" || input.UrlImg == "" {
+		return models.EventInput{}, ErrIncompleteEvent
+	}
+	for _, raw := range []string{input.Url, input.UrlImg, input.UrlBuy} {
+		if raw != "" && !isHTTPURL(raw) {
+			return models.EventInput{}, ErrInvalidEventURL
+		}
+	}
+	return input, nil
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/logger"
+	"github.com/UdinSemen/moscow-events-backend/internal/storage"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+)
+
+const janitorServiceOpPrefix = "services.janitor."
+
+// JanitorService garbage collects the event groups left behind by imports.
+type JanitorService struct {
+	postgres    storage.PgStorage
+	interval    time.Duration
+	groupMaxAge time.Duration
+}
+
+func NewJanitorService(postgres storage.PgStorage, interval, groupMaxAge time.Duration) *JanitorService {
+	return &JanitorService{
+		postgres:    postgres,
+		interval:    interval,
+		groupMaxAge: groupMaxAge,
+	}
+}
+
+// Run cleans up every interval until the context is done. It returns at once if the interval is not set.
+func (s *JanitorService) Run(ctx context.Context) {
+	const op = janitorServiceOpPrefix + "Run"
+
+	if s.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		report, err := s.CleanupEventGroups(ctx)
+		if err != nil {
+			logger.FromContext(ctx).Sugar().Error(fmt.Errorf("%s:%w", op, err))
+		} else if report.Groups > 0 || report.Events > 0 || report.FavouritesMigrated > 0 {
+			logger.FromContext(ctx).Info(op,
+				zap.Int("groups", report.Groups),
+				zap.Int("events", report.Events),
+				zap.Int("dates", report.Dates),
+				zap.Int("favourites_migrated", report.FavouritesMigrated),
+				zap.Int("events_preserved", report.EventsPreserved),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CleanupEventGroups removes the groups that are not actual and older than the max age.
+func (s *JanitorService) CleanupEventGroups(ctx context.Context) (models.GroupsCleanup, error) {
+	const op = janitorServiceOpPrefix + "CleanupEventGroups"
+
+	report, err := s.postgres.DeleteStaleEventGroups(ctx, time.Now().Add(-s.groupMaxAge))
+	if err != nil {
+		return models.GroupsCleanup{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return report, nil
+}
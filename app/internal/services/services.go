@@ -7,33 +7,82 @@ import (
 	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
 	jwtmanager "github.com/UdinSemen/moscow-events-backend/internal/jwt-manager"
 	"github.com/UdinSemen/moscow-events-backend/internal/storage"
+	"github.com/UdinSemen/moscow-events-backend/internal/telegram"
 )
 
 type Auth interface {
 	CreateRegSession(ctx context.Context, fingerPrint string) (string, error)
-	GetRegSession(ctx context.Context, fingerPrint, timeCode string) (string, error)
+	GetRegSession(ctx context.Context, fingerPrint, timeCode, ip string) (string, error)
+	WaitRegSession(ctx context.Context, fingerPrint, timeCode, ip string) (string, error)
 	GetUserDTOByTg(ctx context.Context, userTgId string) (models.UserDTO, error)
-	InitSession(ctx context.Context, userID, refreshToken, ip, fingerprint string) error
-	GenerateTokens(ctx context.Context, userID, role string) (string, string, error)
-	RefreshToken(ctx context.Context, refreshToken, fingerprint string) (string, string, error)
+	NewSessionID(ctx context.Context) (string, error)
+	InitSession(ctx context.Context, sessionID, userID, refreshToken, ip, fingerprint string) error
+	GenerateTokens(ctx context.Context, userID, role, sessionID string) (string, string, error)
+	RefreshToken(ctx context.Context, refreshToken, fingerprint, ip string) (string, string, error)
 	RefreshSession(ctx context.Context, refreshTokenOld, refreshTokenNew, ip string) error
+	Logout(ctx context.Context, user models.UserDTO, refreshToken string) error
+	LogoutAll(ctx context.Context, userID string) error
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	IsSessionRevoked(ctx context.Context, sessionID string) (bool, error)
+	GetActiveSessions(ctx context.Context, user models.UserDTO) ([]models.ActiveSession, error)
 }
 
 type Event interface {
-	GetEvents(ctx context.Context, userID, category string, date []time.Time) ([]models.Event, error)
+	GetEvents(ctx context.Context, userID string, filter models.EventFilter, page models.EventPage) ([]models.Event, string, error)
+	GetEventByID(ctx context.Context, eventID string) (models.EventDetails, error)
+	GetEvent(ctx context.Context, userID, eventID string) (models.EventDetails, error)
+	SearchEvents(ctx context.Context, userID string, search models.EventSearch) ([]models.EventSearchResult, error)
+	CreateEvent(ctx context.Context, input models.EventInput) (string, error)
+	UpdateEvent(ctx context.Context, eventID string, input models.EventInput) error
+	DeleteEvent(ctx context.Context, eventID string) error
+	ImportEvents(ctx context.Context, batch models.EventImport) (models.ImportReport, error)
+	AddFavourite(ctx context.Context, userID, eventID, dateID string) error
+	RemoveFavourite(ctx context.Context, userID, eventID, dateID string) error
+	GetFavourites(ctx context.Context, userID string, limit, offset int) ([]models.Event, int, error)
+}
+
+type Category interface {
+	GetCategories(ctx context.Context, dateFrom, dateTo time.Time) ([]models.Category, error)
+}
+
+type Access interface {
+	HasPermissions(ctx context.Context, role string, permissions ...string) (bool, error)
+}
+
+type Health interface {
+	SetShuttingDown()
+	Ready(ctx context.Context) models.HealthReport
+}
+
+type Telegram interface {
+	VerifyWebhookSecret(secret string) bool
+	HandleUpdate(ctx context.Context, update models.TgUpdate) error
+	AuthWidget(ctx context.Context, auth models.TgWidgetAuth) (models.UserDTO, error)
 }
 
 type Service struct {
 	Auth
 	Event
+	Category
+	Access
+	Telegram
+	Health
 }
 
 func NewService(redis storage.Redis,
 	postgres storage.PgStorage,
-	refreshTTL time.Duration,
-	jwtManager jwtmanager.TokenManager) *Service {
+	refreshTTL,
+	accessTTL time.Duration,
+	jwtManager jwtmanager.TokenManager,
+	bot telegram.BotAPI,
+	tgBotToken,
+	tgWebhookSecret string) *Service {
 	return &Service{
-		Auth:  NewAuth(redis, postgres, refreshTTL, jwtManager),
-		Event: NewEventService(postgres),
+		Auth:     NewAuth(redis, postgres, refreshTTL, accessTTL, jwtManager),
+		Event:    NewEventService(postgres, redis),
+		Category: NewCategoryService(postgres, redis),
+		Access:   NewAccessService(postgres),
+		Telegram: NewTelegramService(redis, postgres, bot, tgBotToken, tgWebhookSecret),
+		Health:   NewHealthService(redis, postgres),
 	}
 }
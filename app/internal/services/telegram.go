@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/logger"
+	"github.com/UdinSemen/moscow-events-backend/internal/storage"
+	storageRedis "github.com/UdinSemen/moscow-events-backend/internal/storage/redis"
+	"github.com/UdinSemen/moscow-events-backend/internal/telegram"
+	"github.com/UdinSemen/moscow-events-backend/internal/tracing"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	telegramServiceOpPrefix = "services.telegram."
+	startCommand            = "/start"
+	attemptsKeyTgUser       = "tg."
+
+	tgReplyConfirmed     = "Вход подтверждён, вернитесь в приложение."
+	tgReplyCodeNotFound  = "Код не найден или устарел, запросите новый в приложении."
+	tgReplyHelp          = "Отправьте код из приложения, чтобы войти."
+	tgReplyTooManyTrials = "Слишком много неверных кодов, попробуйте позже."
+
+	widgetAuthTTL = 24 * time.Hour
+	// widgetClockSkew is how far ahead of our clock telegram's auth_date may be
+	widgetClockSkew = time.Minute
+)
+
+var (
+	ErrInvalidWidgetHash = errors.New("invalid telegram widget hash")
+	ErrWidgetAuthExpired = errors.New("telegram widget auth expired")
+	ErrWidgetAuthFuture  = errors.New("telegram widget auth date is in the future")
+)
+
+type TelegramService struct {
+	redis         storage.Redis
+	postgres      storage.PgStorage
+	bot           telegram.BotAPI
+	botToken      string
+	webhookSecret string
+}
+
+func NewTelegramService(redis storage.Redis,
+	postgres storage.PgStorage,
+	bot telegram.BotAPI,
+	botToken,
+	webhookSecret string) *TelegramService {
+	return &TelegramService{
+		redis:         redis,
+		postgres:      postgres,
+		bot:           bot,
+		botToken:      botToken,
+		webhookSecret: webhookSecret,
+	}
+}
+
+// VerifyWebhookSecret checks the secret token telegram sends with every webhook call.
+// The webhook is disabled while no secret or no bot is configured.
+func (s *TelegramService) VerifyWebhookSecret(secret string) bool {
+	if s.webhookSecret == "" || s.bot == nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(s.webhookSecret)) == 1
+}
+
+// HandleUpdate confirms the reg session of the time code sent as "/start <time_code>" or as a bare
+// code, creating the user of the telegram account on the first sign in.
+func (s *TelegramService) HandleUpdate(ctx context.Context, update models.TgUpdate) error {
+	const op = telegramServiceOpPrefix + "HandleUpdate"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	msg := update.Message
+	if msg == nil || msg.From == nil || msg.From.IsBot {
+		return nil
+	}
+
+	timeCode, ok := parseTimeCode(msg.Text)
+	if !ok {
+		return s.reply(ctx, msg.Chat.ID, tgReplyHelp)
+	}
+
+	// codes are short, so guessing them from a telegram account is limited the same way as in sign in
+	userTgID := strconv.FormatInt(msg.From.ID, 10)
+	attemptsKey := attemptsKeyTgUser + userTgID
+	retryAfter, err := s.redis.LockTTL(ctx, attemptsKey)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if retryAfter > 0 {
+		return s.reply(ctx, msg.Chat.ID, tgReplyTooManyTrials)
+	}
+
+	// the user is created for a pending code only, so sending random numbers doesn't fill users
+	val, err := s.redis.GetRegSession(ctx, timeCode)
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+		return s.codeNotFound(ctx, attemptsKey, msg.Chat.ID)
+	}
+	var session models.RegSession
+	if err := json.Unmarshal([]byte(val), &session); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if session.IsConfirmed && session.UserID != userTgID {
+		return s.codeNotFound(ctx, attemptsKey, msg.Chat.ID)
+	}
+
+	if _, err := s.postgres.UpsertTgUser(ctx, models.User{
+		TgUserID:  userTgID,
+		FirstName: msg.From.FirstName,
+		LastName:  msg.From.LastName,
+	}); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	// the code may expire meanwhile, and a code confirmed by someone else is not theirs to take over
+	err = s.redis.ConfirmRegSession(ctx, timeCode, userTgID)
+	if err != nil {
+		if !errors.Is(err, redis.Nil) && !errors.Is(err, storageRedis.ErrRegSessionTaken) {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+		return s.codeNotFound(ctx, attemptsKey, msg.Chat.ID)
+	}
+
+	if err := s.redis.ResetFailures(ctx, attemptsKey); err != nil {
+		logger.FromContext(ctx).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
+	}
+
+	return s.reply(ctx, msg.Chat.ID, tgReplyConfirmed)
+}
+
+// codeNotFound counts a wrong code of the telegram user, locking them out after too many.
+func (s *TelegramService) codeNotFound(ctx context.Context, attemptsKey string, chatID int64) error {
+	const op = telegramServiceOpPrefix + "codeNotFound"
+
+	failures, err := s.redis.RegisterFailure(ctx, attemptsKey, signInFailuresWindow)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if failures >= maxFingerPrintFailures {
+		if err := s.redis.Lock(ctx, attemptsKey, signInBackoff(failures-maxFingerPrintFailures)); err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+	}
+	return s.reply(ctx, chatID, tgReplyCodeNotFound)
+}
+
+// AuthWidget verifies the Login Widget payload and returns the user of the telegram account, creating it
+// on the first sign in.
+func (s *TelegramService) AuthWidget(ctx context.Context, auth models.TgWidgetAuth) (models.UserDTO, error) {
+	const op = telegramServiceOpPrefix + "AuthWidget"
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if !s.checkWidgetHash(auth) {
+		return models.UserDTO{}, fmt.Errorf("%s:%w", op, ErrInvalidWidgetHash)
+	}
+	if err := checkWidgetAuthDate(auth.AuthDate, time.Now()); err != nil {
+		return models.UserDTO{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	userDTO, err := s.postgres.UpsertTgUser(ctx, models.User{
+		TgUserID:  strconv.FormatInt(auth.ID, 10),
+		FirstName: auth.FirstName,
+		LastName:  auth.LastName,
+	})
+	if err != nil {
+		return models.UserDTO{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return userDTO, nil
+}
+
+// checkWidgetHash validates the payload as described in https://core.telegram.org/widgets/login:
+// the hash is the HMAC-SHA256 of the sorted "key=value" lines keyed with SHA256 of the bot token.
+func (s *TelegramService) checkWidgetHash(auth models.TgWidgetAuth) bool {
+	// an empty token is a key anyone knows
+	if s.botToken == "" {
+		return false
+	}
+
+	fields := map[string]string{
+		"id":         strconv.FormatInt(auth.ID, 10),
+		"first_name": auth.FirstName,
+		"last_name":  auth.LastName,
+		"username":   auth.Username,
+		"photo_url":  auth.PhotoURL,
+		"auth_date":  strconv.FormatInt(auth.AuthDate, 10),
+	}
+
+	lines := make([]string, 0, len(fields))
+	for key, value := range fields {
+		if value != "" {
+			lines = append(lines, key+"="+value)
+		}
+	}
+	sort.Strings(lines)
+
+	secretKey := sha256.Sum256([]byte(s.botToken))
+	mac := hmac.New(sha256.New, secretKey[:])
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(auth.Hash)))
+}
+
+// checkWidgetAuthDate accepts payloads signed within the TTL. A date in the future would prolong the TTL,
+// so only a small clock skew is allowed.
+func checkWidgetAuthDate(authDate int64, now time.Time) error {
+	signed := time.Unix(authDate, 0)
+	if now.Sub(signed) > widgetAuthTTL {
+		return ErrWidgetAuthExpired
+	}
+	if signed.Sub(now) > widgetClockSkew {
+		return ErrWidgetAuthFuture
+	}
+	return nil
+}
+
+// reply sends a message to the chat. A failed reply doesn't undo the handled update, so it's only logged.
+func (s *TelegramService) reply(ctx context.Context, chatID int64, text string) error {
+	const op = telegramServiceOpPrefix + "reply"
+
+	if err := s.bot.SendMessage(ctx, chatID, text); err != nil {
+		logger.FromContext(ctx).Sugar().Warn(fmt.Errorf("%s:%w", op, err))
+	}
+	return nil
+}
+
+// parseTimeCode accepts "/start <code>", "/start@bot_name <code>" and a bare code.
+func parseTimeCode(text string) (string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 2 && (fields[0] == startCommand || strings.HasPrefix(fields[0], startCommand+"@")) {
+		fields = fields[1:]
+	}
+	if len(fields) != 1 || len(fields[0]) != timeCodeLen {
+		return "", false
+	}
+	for _, r := range fields[0] {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+	return fields[0], true
+}
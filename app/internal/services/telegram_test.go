@@ -0,0 +1,92 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+)
+
+const testBotToken = "123:secret"
+
+// signWidget signs the data check string the way telegram does.
+func signWidget(token, dataCheck string) string {
+	secretKey := sha256.Sum256([]byte(token))
+	mac := hmac.New(sha256.New, secretKey[:])
+	mac.Write([]byte(dataCheck))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestCheckWidgetHash(t *testing.T) {
+	s := &TelegramService{botToken: testBotToken}
+	auth := models.TgWidgetAuth{
+		ID:        100,
+		FirstName: "Ivan",
+		Username:  "ivan",
+		AuthDate:  1714521600,
+	}
+	// empty fields are left out, the rest is sorted by key
+	dataCheck := "auth_date=1714521600\nfirst_name=Ivan\nid=100\nusername=ivan"
+
+	tests := []struct {
+		name string
+		edit func(a *models.TgWidgetAuth)
+		want bool
+	}{
+		{name: "valid", edit: func(a *models.TgWidgetAuth) {}, want: true},
+		{name: "upper case hash", edit: func(a *models.TgWidgetAuth) { a.Hash = strings.ToUpper(a.Hash) }, want: true},
+		{name: "edited field", edit: func(a *models.TgWidgetAuth) { a.FirstName = "Petr" }, want: false},
+		{name: "added field", edit: func(a *models.TgWidgetAuth) { a.LastName = "Ivanov" }, want: false},
+		{name: "other bot", edit: func(a *models.TgWidgetAuth) { a.Hash = signWidget("456:other", dataCheck) }, want: false},
+		{name: "no hash", edit: func(a *models.TgWidgetAuth) { a.Hash = "" }, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := auth
+			a.Hash = signWidget(testBotToken, dataCheck)
+			tt.edit(&a)
+			if got := s.checkWidgetHash(a); got != tt.want {
+				t.Errorf("checkWidgetHash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckWidgetHashWithoutToken(t *testing.T) {
+	s := &TelegramService{}
+	auth := models.TgWidgetAuth{ID: 100, AuthDate: 1714521600}
+	auth.Hash = signWidget("", "auth_date=1714521600\nid=100")
+
+	if s.checkWidgetHash(auth) {
+		t.Error("a payload signed with the empty token is accepted")
+	}
+}
+
+func TestCheckWidgetAuthDate(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		authDate time.Time
+		wantErr  error
+	}{
+		{name: "just signed", authDate: now},
+		{name: "within the ttl", authDate: now.Add(-widgetAuthTTL + time.Minute)},
+		{name: "expired", authDate: now.Add(-widgetAuthTTL - time.Second), wantErr: ErrWidgetAuthExpired},
+		{name: "clock skew", authDate: now.Add(widgetClockSkew)},
+		{name: "in the future", authDate: now.Add(widgetClockSkew + time.Second), wantErr: ErrWidgetAuthFuture},
+		{name: "far future", authDate: now.Add(widgetAuthTTL), wantErr: ErrWidgetAuthFuture},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkWidgetAuthDate(tt.authDate.Unix(), now); !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -9,15 +9,44 @@ import (
 )
 
 type PgStorage interface {
-	Ping() error
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (int, error)
 	InitSession(ctx context.Context,
+		sessionID,
 		userTgID string,
-		refreshToken,
+		refreshTokenHash,
 		ip,
 		fingerprint string,
 		expireAt time.Time) error
-	GetSession(ctx context.Context, refreshToken string) (models.Session, error)
+	GetSession(ctx context.Context, refreshTokenHash string) (models.Session, error)
 	GetUserDTO(ctx context.Context, input storage.InputGetUserDTO, typeId string) (models.UserDTO, error)
-	RefreshSession(ctx context.Context, refreshTokenOld, refreshTokenNew, ip string, expireAt time.Time) error
-	GetEvents(ctx context.Context, userID, category string, date []time.Time) ([]models.Event, error)
+	RefreshSession(ctx context.Context, refreshTokenHashOld, refreshTokenHashNew, ip string, expireAt time.Time) error
+	GetRotatedSession(ctx context.Context, refreshTokenHash string) (models.Session, error)
+	AddAuditEvent(ctx context.Context, event models.AuditEvent) error
+	DeleteSession(ctx context.Context, userID, sessionID string) error
+	DeleteSessionByToken(ctx context.Context, userID, refreshTokenHash string) (string, error)
+	DeleteUserSessions(ctx context.Context, userID string) ([]string, error)
+	GetUserSessions(ctx context.Context, userID string) ([]models.Session, error)
+	UpsertTgUser(ctx context.Context, user models.User) (models.UserDTO, error)
+	HashLegacyRefreshTokens(ctx context.Context, hash func(token string) string) (int, error)
+	GetEvents(ctx context.Context,
+		userID string,
+		filter models.EventFilter,
+		sort string,
+		limit int,
+		after *models.EventCursor) ([]models.Event, *models.EventCursor, error)
+	GetEventByID(ctx context.Context, eventID string) (models.EventDetails, error)
+	GetEvent(ctx context.Context, userID, eventID string) (models.EventDetails, error)
+	SearchEvents(ctx context.Context, userID string, search models.EventSearch) ([]models.EventSearchResult, error)
+	CreateEvent(ctx context.Context, input models.EventInput) (string, error)
+	UpdateEvent(ctx context.Context, eventID string, input models.EventInput) error
+	DeleteEvent(ctx context.Context, eventID string) error
+	ImportEvents(ctx context.Context, src, category string, events []models.EventInput) (string, error)
+	DeleteStaleEventGroups(ctx context.Context, before time.Time) (models.GroupsCleanup, error)
+	NormalizeEventPrices(ctx context.Context, parse func(price string) models.EventPrice, all bool) (int, error)
+	GetCategories(ctx context.Context, dateFrom, dateTo time.Time) ([]models.Category, error)
+	GetRolePermissions(ctx context.Context, role string) ([]string, error)
+	AddFavourite(ctx context.Context, userID, eventID, dateID string) error
+	RemoveFavourite(ctx context.Context, userID, eventID, dateID string) error
+	GetFavourites(ctx context.Context, userID string, limit, offset int) ([]models.Event, int, error)
 }
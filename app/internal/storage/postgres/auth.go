@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/metrics"
 	"golang.org/x/net/context"
 )
 
@@ -15,14 +16,16 @@ const opPrefixPgStorageAuth = "pg_storage.auth."
 
 func (s *PgStorage) InitSession(
 	ctx context.Context,
+	sessionID,
 	userTgID string,
-	refreshToken,
+	refreshTokenHash,
 	ip,
 	fingerprint string,
 	expireAt time.Time) error {
 	const op = opPrefixPgStorageAuth + "InitUser"
+	defer metrics.ObserveStorage(storageName, op)()
 
-	tx, err := s.db.Begin()
+	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -36,9 +39,9 @@ func (s *PgStorage) InitSession(
 		return outErr
 	}
 
-	_, err = tx.Exec("insert into sessions (user_id, refresh_token, ip, finger_print, exp_at) "+
-		"values ($1, $2, $3, $4, $5)",
-		uuid, refreshToken, ip, fingerprint, expireAt)
+	_, err = tx.ExecContext(ctx, "insert into sessions (id, user_id, refresh_token_hash, ip, finger_print, exp_at) "+
+		"values ($1, $2, $3, $4, $5, $6)",
+		sessionID, uuid, refreshTokenHash, ip, fingerprint, expireAt)
 	if err != nil {
 		_ = tx.Rollback()
 		return fmt.Errorf("%s: %w", op, err)
@@ -47,12 +50,13 @@ func (s *PgStorage) InitSession(
 	return tx.Commit()
 }
 
-func (s *PgStorage) GetSession(ctx context.Context, refreshToken string) (models.Session, error) {
+func (s *PgStorage) GetSession(ctx context.Context, refreshTokenHash string) (models.Session, error) {
 	const op = opPrefixPgStorageAuth + "GetSession"
+	defer metrics.ObserveStorage(storageName, op)()
 
 	var session models.Session
-	query := "select s.user_id, s.finger_print, s.exp_at from sessions s where s.refresh_token=$1"
-	if err := s.db.Get(&session, query, refreshToken); err != nil {
+	query := "select s.id, s.user_id, s.finger_print, s.exp_at from sessions s where s.refresh_token_hash=$1"
+	if err := s.db.GetContext(ctx, &session, query, refreshTokenHash); err != nil {
 		outErr := fmt.Errorf("%s:%w", op, err)
 		if errors.Is(err, sql.ErrNoRows) {
 			outErr = fmt.Errorf("%s:%w", op, ErrNoRows)
@@ -70,6 +74,7 @@ type InputGetUserDTO struct {
 
 func (s *PgStorage) GetUserDTO(ctx context.Context, input InputGetUserDTO, typeId string) (models.UserDTO, error) {
 	const op = opPrefixPgStorageAuth + "GetUserDTO"
+	defer metrics.ObserveStorage(storageName, op)()
 
 	if !slices.Contains([]string{TypeTgID, TypeUUID}, typeId) {
 		return models.UserDTO{}, ErrInvalidIDType
@@ -101,17 +106,205 @@ func (s *PgStorage) GetUserDTO(ctx context.Context, input InputGetUserDTO, typeI
 	return model, nil
 }
 
-func (s *PgStorage) RefreshSession(ctx context.Context, refreshTokenOld, refreshTokenNew, ip string, expireAt time.Time) error {
+func (s *PgStorage) RefreshSession(ctx context.Context, refreshTokenHashOld, refreshTokenHashNew, ip string, expireAt time.Time) error {
 	const op = opPrefixPgStorageAuth + "RefreshSession"
+	defer metrics.ObserveStorage(storageName, op)()
 
-	query := "update sessions set refresh_token=:refresh_token_new, " +
-		"ip=:ip, exp_at=:exp_at where refresh_token=:refresh_token_old "
-	_, err := s.db.NamedExecContext(ctx, query, map[string]interface{}{
-		"refresh_token_new": refreshTokenNew,
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := "update sessions set refresh_token_hash=:refresh_token_new, " +
+		"ip=:ip, exp_at=:exp_at, updated_at=now() where refresh_token_hash=:refresh_token_old returning id, user_id"
+	rows, err := tx.NamedQuery(query, map[string]interface{}{
+		"refresh_token_new": refreshTokenHashNew,
 		"ip":                ip,
 		"exp_at":            expireAt,
-		"refresh_token_old": refreshTokenOld,
+		"refresh_token_old": refreshTokenHashOld,
 	})
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	// no row means the token was rotated concurrently
+	var sessionID, userID string
+	if !rows.Next() {
+		_ = rows.Close()
+		return fmt.Errorf("%s:%w", op, ErrNoRows)
+	}
+	if err := rows.Scan(&sessionID, &userID); err != nil {
+		_ = rows.Close()
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	// the rotated token is remembered so that a replay of it can be told apart from an unknown token
+	_, err = tx.ExecContext(ctx, "insert into refresh_token_history (session_id, user_id, refresh_token_hash) "+
+		"values ($1, $2, $3) on conflict (refresh_token_hash) do nothing",
+		sessionID, userID, refreshTokenHashOld)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+// GetRotatedSession finds the session a previously rotated refresh token belonged to.
+func (s *PgStorage) GetRotatedSession(ctx context.Context, refreshTokenHash string) (models.Session, error) {
+	const op = opPrefixPgStorageAuth + "GetRotatedSession"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	var session models.Session
+	query := "select s.id, s.user_id, s.finger_print, s.exp_at from refresh_token_history h " +
+		"join sessions s on s.id = h.session_id where h.refresh_token_hash = $1"
+	if err := s.db.GetContext(ctx, &session, query, refreshTokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Session{}, fmt.Errorf("%s:%w", op, ErrNoRows)
+		}
+		return models.Session{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return session, nil
+}
+
+func (s *PgStorage) AddAuditEvent(ctx context.Context, event models.AuditEvent) error {
+	const op = opPrefixPgStorageAuth + "AddAuditEvent"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	query := "insert into auth_audit (user_id, session_id, event, ip, finger_print, details) " +
+		"values (cast(nullif(:user_id, '') as uuid), cast(nullif(:session_id, '') as uuid), :event, :ip, :finger_print, :details)"
+	if _, err := s.db.NamedExecContext(ctx, query, event); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+func (s *PgStorage) DeleteSession(ctx context.Context, userID, sessionID string) error {
+	const op = opPrefixPgStorageAuth + "DeleteSession"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	res, err := s.db.ExecContext(ctx, "delete from sessions where id = $1 and user_id = $2", sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s:%w", op, ErrNoRows)
+	}
+
+	return nil
+}
+
+func (s *PgStorage) DeleteSessionByToken(ctx context.Context, userID, refreshTokenHash string) (string, error) {
+	const op = opPrefixPgStorageAuth + "DeleteSessionByToken"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	var sessionID string
+	query := "delete from sessions where refresh_token_hash = $1 and user_id = $2 returning id"
+	if err := s.db.GetContext(ctx, &sessionID, query, refreshTokenHash, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("%s:%w", op, ErrNoRows)
+		}
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+
+	return sessionID, nil
+}
+
+func (s *PgStorage) DeleteUserSessions(ctx context.Context, userID string) ([]string, error) {
+	const op = opPrefixPgStorageAuth + "DeleteUserSessions"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	var sessionIDs []string
+	if err := s.db.SelectContext(ctx, &sessionIDs,
+		"delete from sessions where user_id = $1 returning id", userID); err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return sessionIDs, nil
+}
+
+func (s *PgStorage) GetUserSessions(ctx context.Context, userID string) ([]models.Session, error) {
+	const op = opPrefixPgStorageAuth + "GetUserSessions"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	var sessions []models.Session
+	query := "select s.id, s.user_id, coalesce(s.finger_print, '') as finger_print, s.exp_at, " +
+		"coalesce(s.ip, '') as ip, s.created_at, s.updated_at from sessions s " +
+		"where s.user_id = $1 and s.exp_at > now() order by coalesce(s.updated_at, s.created_at) desc"
+	if err := s.db.SelectContext(ctx, &sessions, query, userID); err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return sessions, nil
+}
+
+// HashLegacyRefreshTokens replaces refresh tokens stored in plaintext by databases created before
+// tokens were hashed. It returns the number of converted rows and is a no-op once nothing is left.
+func (s *PgStorage) HashLegacyRefreshTokens(ctx context.Context, hash func(token string) string) (int, error) {
+	const op = opPrefixPgStorageAuth + "HashLegacyRefreshTokens"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var converted int
+	for _, table := range []string{"sessions", "refresh_token_history"} {
+		var rows []struct {
+			ID           string `db:"id"`
+			RefreshToken string `db:"refresh_token"`
+		}
+		query := fmt.Sprintf("select id, refresh_token from %s where refresh_token is not null for update", table)
+		if err := tx.SelectContext(ctx, &rows, query); err != nil {
+			return 0, fmt.Errorf("%s:%w", op, err)
+		}
+
+		update := fmt.Sprintf("update %s set refresh_token_hash = $1, refresh_token = null where id = $2", table)
+		for _, row := range rows {
+			if _, err := tx.ExecContext(ctx, update, hash(row.RefreshToken), row.ID); err != nil {
+				return 0, fmt.Errorf("%s:%w", op, err)
+			}
+		}
+		converted += len(rows)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return converted, nil
+}
+
+// UpsertTgUser creates the user of a telegram account or refreshes the names of an existing one.
+func (s *PgStorage) UpsertTgUser(ctx context.Context, user models.User) (models.UserDTO, error) {
+	const op = opPrefixPgStorageAuth + "UpsertTgUser"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	var userDTO models.UserDTO
+	query := "insert into users (tg_user_id, first_name, last_name, role) values ($1, $2, $3, $4) " +
+		"on conflict (tg_user_id) do update set first_name = excluded.first_name, " +
+		"last_name = excluded.last_name, updated_at = now() " +
+		"returning id, coalesce(role, '') as role"
+	if err := s.db.GetContext(ctx, &userDTO, query,
+		user.TgUserID, user.FirstName, user.LastName, models.RoleUser); err != nil {
+		return models.UserDTO{}, fmt.Errorf("%s:%w", op, err)
+	}
 
-	return err
+	return userDTO, nil
 }
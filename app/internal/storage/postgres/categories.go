@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/metrics"
+	"golang.org/x/net/context"
+)
+
+const opPrefixPgStorageCategories = "pg_storage.categories."
+
+// GetCategories lists the categories of the actual groups with the number of events that have a date
+// in the window, counting the events the listing shows. Categories without a row in the catalogue are
+// named by their slug.
+func (s *PgStorage) GetCategories(ctx context.Context, dateFrom, dateTo time.Time) ([]models.Category, error) {
+	const op = opPrefixPgStorageCategories + "GetCategories"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	query := "select g.category as slug, coalesce(c.name, g.category) as name, coalesce(c.icon, '') as icon, " +
+		"coalesce(c.color, '') as color, coalesce(c.sort_order, 0) as sort_order, " +
+		"count(distinct ev.id) filter (where d.id is not null) as count " +
+		"from (select distinct category, id_group from news_events_actual_group where category is not null) g " +
+		"left join categories c on c.slug = g.category " +
+		"left join news_events ev on ev.id_group = g.id_group and ev.category = g.category and " + visibleEventCond + " " +
+		"left join dates d on d.id_event = ev.id and d.date between $1 and $2 " +
+		"group by g.category, c.name, c.icon, c.color, c.sort_order " +
+		"order by sort_order, name"
+
+	categories := make([]models.Category, 0)
+	if err := s.db.SelectContext(ctx, &categories, query, dateFrom, dateTo); err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return categories, nil
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/metrics"
+	"github.com/lib/pq"
+	"golang.org/x/net/context"
+)
+
+const opPrefixPgStorageCleanup = "pg_storage.cleanup."
+
+// favouriteMoves maps favourites on events of the stale groups $1 to the same date of the event with the
+// same label in the actual group of the source and category. Every favourite and every new user, event
+// and date triple is mapped at most once.
+const favouriteMoves = "select distinct on (m.user_id, m.event_id, m.date_id) m.fav_id, m.user_id, m.event_id, m.date_id " +
+	"from (select distinct on (fv.id) fv.id as fav_id, fv.user_id, nev.id as event_id, nd.id as date_id " +
+	"from favourite_list fv " +
+	"join news_events ev on ev.id = fv.id_event " +
+	"join dates d on d.id = fv.id_date " +
+	"join news_events_actual_group g on g.src = ev.src and g.category = ev.category " +
+	"join news_events nev on nev.id_group = g.id_group and nev.label = ev.label " +
+	"join dates nd on nd.id_event = nev.id and nd.date = d.date " +
+	"where ev.id_group = any(cast($1 as uuid[])) " +
+	"order by fv.id, nev.created_at desc) m " +
+	"order by m.user_id, m.event_id, m.date_id, m.fav_id"
+
+// DeleteStaleEventGroups removes the events and dates of groups that stopped being actual before the
+// given time. Favourites on them are moved to the matching actual events where possible, the events
+// that keep favourites are preserved and their group is only counted once it's gone.
+func (s *PgStorage) DeleteStaleEventGroups(ctx context.Context, before time.Time) (models.GroupsCleanup, error) {
+	const op = opPrefixPgStorageCleanup + "DeleteStaleEventGroups"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.GroupsCleanup{}, fmt.Errorf("%s:%w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var groups []string
+	if err := tx.SelectContext(ctx, &groups, "select sg.id_group from news_events_stale_group sg "+
+		"where sg.deactivated_at < $1 and not exists "+
+		"(select 1 from news_events_actual_group g where g.id_group = sg.id_group) for update", before); err != nil {
+		return models.GroupsCleanup{}, fmt.Errorf("%s:%w", op, err)
+	}
+	if len(groups) == 0 {
+		return models.GroupsCleanup{}, nil
+	}
+	stale := pq.Array(groups)
+	var report models.GroupsCleanup
+
+	res, err := tx.ExecContext(ctx, "update favourite_list fv set id_event = m.event_id, id_date = m.date_id "+
+		"from ("+favouriteMoves+") m where fv.id = m.fav_id and not exists "+
+		"(select 1 from favourite_list o where o.user_id = m.user_id and o.id_event = m.event_id and o.id_date = m.date_id)",
+		stale)
+	if err != nil {
+		return models.GroupsCleanup{}, fmt.Errorf("%s:%w", op, err)
+	}
+	if report.FavouritesMigrated, err = rowsAffected(res); err != nil {
+		return models.GroupsCleanup{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	// favourites whose actual event is already a favourite of the user are merged into it
+	res, err = tx.ExecContext(ctx, "delete from favourite_list fv using ("+favouriteMoves+") m "+
+		"where fv.id = m.fav_id and exists "+
+		"(select 1 from favourite_list o where o.user_id = m.user_id and o.id_event = m.event_id and o.id_date = m.date_id)",
+		stale)
+	if err != nil {
+		return models.GroupsCleanup{}, fmt.Errorf("%s:%w", op, err)
+	}
+	merged, err := rowsAffected(res)
+	if err != nil {
+		return models.GroupsCleanup{}, fmt.Errorf("%s:%w", op, err)
+	}
+	report.FavouritesMigrated += merged
+
+	res, err = tx.ExecContext(ctx, "delete from dates d using news_events ev "+
+		"where d.id_event = ev.id and ev.id_group = any(cast($1 as uuid[])) "+
+		"and not exists (select 1 from favourite_list fv where fv.id_event = ev.id)", stale)
+	if err != nil {
+		return models.GroupsCleanup{}, fmt.Errorf("%s:%w", op, err)
+	}
+	if report.Dates, err = rowsAffected(res); err != nil {
+		return models.GroupsCleanup{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	res, err = tx.ExecContext(ctx, "delete from news_events ev where ev.id_group = any(cast($1 as uuid[])) "+
+		"and not exists (select 1 from favourite_list fv where fv.id_event = ev.id)", stale)
+	if err != nil {
+		return models.GroupsCleanup{}, fmt.Errorf("%s:%w", op, err)
+	}
+	if report.Events, err = rowsAffected(res); err != nil {
+		return models.GroupsCleanup{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	if err := tx.GetContext(ctx, &report.EventsPreserved,
+		"select count(*) from news_events where id_group = any(cast($1 as uuid[]))", stale); err != nil {
+		return models.GroupsCleanup{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	res, err = tx.ExecContext(ctx, "delete from news_events_stale_group sg where sg.id_group = any(cast($1 as uuid[])) "+
+		"and not exists (select 1 from news_events ev where ev.id_group = sg.id_group)", stale)
+	if err != nil {
+		return models.GroupsCleanup{}, fmt.Errorf("%s:%w", op, err)
+	}
+	if report.Groups, err = rowsAffected(res); err != nil {
+		return models.GroupsCleanup{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.GroupsCleanup{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return report, nil
+}
+
+func rowsAffected(res sql.Result) (int, error) {
+	n, err := res.RowsAffected()
+	return int(n), err
+}
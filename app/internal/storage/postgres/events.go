@@ -5,42 +5,158 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/metrics"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"golang.org/x/net/context"
 )
 
 const opPrefixPgStorageEvents = "pg_storage.events."
 
 var (
-	ErrInvalidDates = errors.New("invalid dates")
+	ErrEventExists = errors.New("event already exists")
+	ErrInvalidSort = errors.New("invalid sort")
 )
 
-func (s *PgStorage) GetEvents(ctx context.Context, userID, category string, date []time.Time) ([]models.Event, error) {
-	const op = opPrefixPgStorageEvents + "GetEvents"
+// visibleEventCond keeps the events of ev complete enough to be shown, listings and their counts share it.
+const visibleEventCond = "ev.url_img NOTNULL AND ev.price NOTNULL AND ev.label NOTNULL AND ev.description NOTNULL"
+
+// priceSortExpr is the lower bound of the normalized price, unknown prices sort as free ones.
+const priceSortExpr = "coalesce(ev.price_min, 0)"
+
+// priceCols selects the normalized price of ev, rows not parsed yet read as unknown prices.
+const priceCols = "ev.price_min, ev.price_max, coalesce(ev.price_currency, '') AS price_currency, " +
+	"coalesce(ev.is_free, false) AS is_free"
 
-	if !slices.Contains([]int{1, 2}, len(date)) {
-		return nil, fmt.Errorf("%s:%w", op, ErrInvalidDates)
+type eventSort struct {
+	order string
+	after string
+}
+
+// eventSorts are the keyset orders of event listings, every order ends with the date id as tie-breaker
+// and after selects the rows following the cursor in that order.
+var eventSorts = map[string]eventSort{
+	models.SortDate: {
+		order: "d.date, d.id",
+		after: "(d.date, d.id) > (:cur_date, cast(:cur_id as uuid))",
+	},
+	models.SortPrice: {
+		order: "sort_price, d.date, d.id",
+		after: "(" + priceSortExpr + ", d.date, d.id) > (:cur_price, :cur_date, cast(:cur_id as uuid))",
+	},
+	models.SortRecent: {
+		order: "ev.created_at DESC, d.id DESC",
+		after: "(ev.created_at, d.id) < (:cur_created_at, cast(:cur_id as uuid))",
+	},
+}
+
+// eventFilterConds translates the filter into " AND ..." conditions over ev, d and fv together with their
+// named arguments. Values are never spliced into the sql.
+func eventFilterConds(filter models.EventFilter) ([]string, map[string]interface{}) {
+	conds := make([]string, 0, 8)
+	args := make(map[string]interface{})
+
+	if len(filter.Categories) > 0 {
+		conds = append(conds, " AND ev.category = any(:categories)")
+		args["categories"] = pq.Array(filter.Categories)
 	}
-	stmt := "where d.date = :date_fir"
-	args := map[string]interface{}{
-		"date_fir": date[0],
-		"cat":      category,
-		"user_id":  userID,
+	if len(filter.Sources) > 0 {
+		conds = append(conds, " AND ev.src = any(:sources)")
+		args["sources"] = pq.Array(filter.Sources)
+	}
+	if filter.DateFrom != nil {
+		conds = append(conds, " AND d.date >= :date_from")
+		args["date_from"] = *filter.DateFrom
+	}
+	if filter.DateTo != nil {
+		conds = append(conds, " AND d.date <= :date_to")
+		args["date_to"] = *filter.DateTo
 	}
-	if len(date) == 2 {
-		stmt = "where d.date between :date_fir and :date_sec"
-		args["date_sec"] = date[1]
+	if filter.FreeOnly {
+		conds = append(conds, " AND ev.is_free")
 	}
+	// events are kept when their price range overlaps the bounds, an unknown price matches no bounds
+	if filter.PriceMin != nil {
+		conds = append(conds, " AND coalesce(ev.price_max, ev.price_min) >= :price_min")
+		args["price_min"] = *filter.PriceMin
+	}
+	if filter.PriceMax != nil {
+		conds = append(conds, " AND (ev.price_min IS NOT NULL OR ev.price_max IS NOT NULL)"+
+			" AND coalesce(ev.price_min, 0) <= :price_max")
+		args["price_max"] = *filter.PriceMax
+	}
+	if filter.OnlyFavourites {
+		conds = append(conds, " AND fv.id_event IS NOT NULL")
+	}
+	if filter.Query != "" {
+		conds = append(conds, " AND ev.search_vector @@ websearch_to_tsquery('russian', :query)")
+		args["query"] = filter.Query
+	}
+
+	return conds, args
+}
 
-	query := fmt.Sprint("SELECT ev.id, label, description, d.date, ev.price, coalesce(ev.url_buy, '') AS url_buy, url_img, CASE WHEN fv.id_event IS NOT NULL THEN TRUE ELSE FALSE END AS is_favorite" +
+type eventRow struct {
+	models.Event
+	SortPrice float64   `db:"sort_price"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// eventsQuery builds the named query of an event listing page, see GetEvents.
+func eventsQuery(userID string,
+	filter models.EventFilter,
+	sort string,
+	limit int,
+	after *models.EventCursor) (string, map[string]interface{}, error) {
+	order, ok := eventSorts[sort]
+	if !ok {
+		return "", nil, ErrInvalidSort
+	}
+
+	conds, args := eventFilterConds(filter)
+	args["user_id"] = userID
+	// one more row tells whether there is a next page
+	args["limit"] = limit + 1
+	if after != nil {
+		conds = append(conds, " AND "+order.after)
+		args["cur_date"] = after.Date
+		args["cur_price"] = after.Price
+		args["cur_created_at"] = after.CreatedAt
+		args["cur_id"] = after.DateId
+	}
+
+	query := fmt.Sprint("SELECT ev.id, label, description, d.id AS date_id, d.date, ev.price, " + priceCols + ", coalesce(ev.url_buy, '') AS url_buy, url_img, CASE WHEN fv.id_event IS NOT NULL THEN TRUE ELSE FALSE END AS is_favorite, " +
+		priceSortExpr + " AS sort_price, ev.created_at" +
 		" FROM public.news_events ev JOIN public.dates d ON ev.id = d.id_event" +
 		" LEFT JOIN favourite_list fv ON ev.id = fv.id_event and fv.id_date = d.id and fv.user_id =:user_id " +
-		stmt +
-		" AND url_img NOTNULL AND price NOTNULL AND label NOTNULL and ev.url_img NOTNULL and ev.description NOTNULL AND ev.category =:cat" +
+		" WHERE " + visibleEventCond +
 		" and id_group in (select id_group from public.news_events_actual_group)" +
-		" ORDER BY date")
+		strings.Join(conds, "") +
+		" ORDER BY " + order.order +
+		" LIMIT :limit")
+
+	return query, args, nil
+}
+
+// GetEvents returns a page of at most limit events matching the filter, ordered by sort and starting after
+// the cursor. The returned cursor points at the last event and is nil when there are no more events.
+func (s *PgStorage) GetEvents(ctx context.Context,
+	userID string,
+	filter models.EventFilter,
+	sort string,
+	limit int,
+	after *models.EventCursor) ([]models.Event, *models.EventCursor, error) {
+	const op = opPrefixPgStorageEvents + "GetEvents"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	query, args, err := eventsQuery(userID, filter, sort, limit, after)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s:%w", op, err)
+	}
 
 	rows, err := s.db.NamedQueryContext(ctx, query, args)
 	if err != nil {
@@ -48,18 +164,397 @@ func (s *PgStorage) GetEvents(ctx context.Context, userID, category string, date
 		if errors.Is(err, sql.ErrNoRows) {
 			outErr = fmt.Errorf("%s:%w", op, ErrNoRows)
 		}
-		return nil, outErr
+		return nil, nil, outErr
 	}
+	defer func() { _ = rows.Close() }()
+
+	events := make([]models.Event, 0, limit)
+	var last eventRow
+	for rows.Next() {
+		var row eventRow
+		if err := rows.StructScan(&row); err != nil {
+			return nil, nil, fmt.Errorf("%s:%w", op, err)
+		}
+		if len(events) == limit {
+			return events, &models.EventCursor{
+				Sort:      sort,
+				Date:      last.Date,
+				Price:     last.SortPrice,
+				CreatedAt: last.CreatedAt,
+				DateId:    last.DateId,
+			}, nil
+		}
+		events = append(events, row.Event)
+		last = row
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return events, nil, nil
+}
+
+func (s *PgStorage) GetEventByID(ctx context.Context, eventID string) (models.EventDetails, error) {
+	const op = opPrefixPgStorageEvents + "GetEventByID"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	event, err := s.getEventDetails(ctx, eventID, "", false)
+	if err != nil {
+		return models.EventDetails{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return event, nil
+}
 
-	var events []models.Event
+// GetEvent returns an actual event as users see it, with the favourite state of every date for the user.
+func (s *PgStorage) GetEvent(ctx context.Context, userID, eventID string) (models.EventDetails, error) {
+	const op = opPrefixPgStorageEvents + "GetEvent"
+	defer metrics.ObserveStorage(storageName, op)()
 
+	event, err := s.getEventDetails(ctx, eventID, userID, true)
+	if err != nil {
+		return models.EventDetails{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return event, nil
+}
+
+func (s *PgStorage) getEventDetails(ctx context.Context, eventID, userID string, onlyActual bool) (models.EventDetails, error) {
+	var event models.EventDetails
+	query := "select ev.id, coalesce(ev.id_group::text, '') as id_group, coalesce(ev.src, '') as src, " +
+		"coalesce(ev.category, '') as category, coalesce(ev.label, '') as label, " +
+		"coalesce(ev.description, '') as description, coalesce(ev.price, '') as price, " + priceCols + ", " +
+		"coalesce(ev.url, '') as url, coalesce(ev.url_img, '') as url_img, coalesce(ev.url_buy, '') as url_buy, " +
+		"ev.created_at, ev.updated_at from news_events ev where ev.id = $1"
+	if onlyActual {
+		query += " and ev.id_group in (select id_group from news_events_actual_group)"
+	}
+	if err := s.db.GetContext(ctx, &event, query, eventID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.EventDetails{}, ErrNoRows
+		}
+		return models.EventDetails{}, err
+	}
+
+	// an empty user id matches no favourites
+	if err := s.db.SelectContext(ctx, &event.Dates,
+		"select d.id, d.date, exists(select 1 from favourite_list fv "+
+			"where fv.id_date = d.id and fv.user_id::text = $2) as is_favorite "+
+			"from dates d where d.id_event = $1 order by d.date", eventID, userID); err != nil {
+		return models.EventDetails{}, err
+	}
+
+	return event, nil
+}
+
+func (s *PgStorage) CreateEvent(ctx context.Context, input models.EventInput) (string, error) {
+	const op = opPrefixPgStorageEvents + "CreateEvent"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var idGroup string
+	row := tx.QueryRowxContext(ctx, "insert into news_events_actual_group (src, category) values ($1, $2) "+
+		"on conflict (src, category) do update set src = excluded.src returning id_group",
+		input.Src, input.Category)
+	if err := row.Scan(&idGroup); err != nil {
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+
+	if err := checkEventLabel(ctx, tx, idGroup, input.Label, ""); err != nil {
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+
+	eventID, err := insertEvent(ctx, tx, idGroup, input)
+	if err != nil {
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+
+	return eventID, nil
+}
+
+func (s *PgStorage) UpdateEvent(ctx context.Context, eventID string, input models.EventInput) error {
+	const op = opPrefixPgStorageEvents + "UpdateEvent"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var idGroup sql.NullString
+	row := tx.QueryRowxContext(ctx, "select id_group from news_events where id = $1 for update", eventID)
+	if err := row.Scan(&idGroup); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%s:%w", op, ErrNoRows)
+		}
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	if err := checkEventLabel(ctx, tx, idGroup.String, input.Label, eventID); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, "update news_events set src = $1, category = $2, label = $3, description = $4, "+
+		"price = $5, price_min = $6, price_max = $7, price_currency = nullif($8, ''), is_free = $9, "+
+		"url = nullif($10, ''), url_img = $11, url_buy = nullif($12, ''), updated_at = now() where id = $13",
+		input.Src, input.Category, input.Label, input.Description, input.Price,
+		input.PriceMin, input.PriceMax, input.PriceCurrency, input.IsFree,
+		input.Url, input.UrlImg, input.UrlBuy, eventID)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	// dates that are kept retain their ids, so favourites pointing at them survive the update
+	var current []models.EventDate
+	if err := tx.SelectContext(ctx, &current, "select d.id, d.date from dates d where d.id_event = $1", eventID); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	for _, date := range current {
+		if slices.ContainsFunc(input.Dates, date.Date.Equal) {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, "delete from favourite_list where id_date = $1", date.Id); err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+		if _, err := tx.ExecContext(ctx, "delete from dates where id = $1", date.Id); err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+	}
+
+	for _, date := range input.Dates {
+		if slices.ContainsFunc(current, func(d models.EventDate) bool { return d.Date.Equal(date) }) {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, "insert into dates (id_event, date) values ($1, $2)", eventID, date); err != nil {
+			return fmt.Errorf("%s:%w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+func (s *PgStorage) DeleteEvent(ctx context.Context, eventID string) error {
+	const op = opPrefixPgStorageEvents + "DeleteEvent"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, "delete from favourite_list where id_event = $1", eventID); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if _, err := tx.ExecContext(ctx, "delete from dates where id_event = $1", eventID); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	res, err := tx.ExecContext(ctx, "delete from news_events where id = $1", eventID)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s:%w", op, ErrNoRows)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+// ImportEvents writes the events under a new group and makes it the actual group of the source and
+// category in the same transaction, so readers switch from the previous batch to the new one at once.
+func (s *PgStorage) ImportEvents(ctx context.Context, src, category string, events []models.EventInput) (string, error) {
+	const op = opPrefixPgStorageEvents + "ImportEvents"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var idGroup string
+	if err := tx.GetContext(ctx, &idGroup, "select gen_random_uuid()"); err != nil {
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+
+	for _, event := range events {
+		if _, err := insertEvent(ctx, tx, idGroup, event); err != nil {
+			return "", fmt.Errorf("%s:%w", op, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "insert into news_events_actual_group (src, category, id_group) "+
+		"values ($1, $2, $3) on conflict (src, category) do update set id_group = excluded.id_group",
+		src, category, idGroup); err != nil {
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+
+	return idGroup, nil
+}
+
+// insertEvent adds the event with its dates to the group.
+func insertEvent(ctx context.Context, tx *sqlx.Tx, idGroup string, input models.EventInput) (string, error) {
+	var eventID string
+	row := tx.QueryRowxContext(ctx, "insert into news_events "+
+		"(id_group, src, category, label, description, price, price_min, price_max, price_currency, is_free, "+
+		"url, url_img, url_buy) "+
+		"values ($1, $2, $3, $4, $5, $6, $7, $8, nullif($9, ''), $10, nullif($11, ''), $12, nullif($13, '')) returning id",
+		idGroup, input.Src, input.Category, input.Label, input.Description, input.Price,
+		input.PriceMin, input.PriceMax, input.PriceCurrency, input.IsFree,
+		input.Url, input.UrlImg, input.UrlBuy)
+	if err := row.Scan(&eventID); err != nil {
+		return "", err
+	}
+
+	for _, date := range input.Dates {
+		if _, err := tx.ExecContext(ctx, "insert into dates (id_event, date) values ($1, $2)", eventID, date); err != nil {
+			return "", err
+		}
+	}
+
+	return eventID, nil
+}
+
+// checkEventLabel returns ErrEventExists if another event of the group already has the label.
+func checkEventLabel(ctx context.Context, tx *sqlx.Tx, idGroup, label, exceptID string) error {
+	var exists bool
+	query := "select exists(select 1 from news_events where id_group::text = $1 and label = $2 and id::text <> $3)"
+	if err := tx.GetContext(ctx, &exists, query, idGroup, label, exceptID); err != nil {
+		return err
+	}
+	if exists {
+		return ErrEventExists
+	}
+	return nil
+}
+
+// SearchEvents runs a full text search with russian stemming over labels and descriptions of the actual
+// events. Every event is returned once, with its earliest date within the optional date range.
+func (s *PgStorage) SearchEvents(ctx context.Context, userID string, search models.EventSearch) ([]models.EventSearchResult, error) {
+	const op = opPrefixPgStorageEvents + "SearchEvents"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	filters := ""
+	dateFilters := ""
+	args := map[string]interface{}{
+		"query":   search.Query,
+		"user_id": userID,
+		"limit":   search.Limit,
+		"offset":  search.Offset,
+	}
+	if search.Category != "" {
+		filters += " AND ev.category = :cat"
+		args["cat"] = search.Category
+	}
+	if search.DateFrom != nil {
+		dateFilters += " AND d.date >= :date_from"
+		args["date_from"] = *search.DateFrom
+	}
+	if search.DateTo != nil {
+		dateFilters += " AND d.date <= :date_to"
+		args["date_to"] = *search.DateTo
+	}
+
+	query := "SELECT ev.id, coalesce(ev.category, '') AS category, ev.label, ev.description, " +
+		"ts_headline('russian', ev.label, q.query, 'HighlightAll=true') AS label_snippet, " +
+		"ts_headline('russian', ev.description, q.query, 'MaxFragments=2, MaxWords=25, MinWords=10') AS description_snippet, " +
+		"ts_rank_cd(ev.search_vector, q.query) AS rank, " +
+		"d.id AS date_id, d.date, ev.price, " + priceCols + ", coalesce(ev.url_buy, '') AS url_buy, ev.url_img, " +
+		"fv.id_event IS NOT NULL AS is_favorite" +
+		" FROM public.news_events ev" +
+		" CROSS JOIN websearch_to_tsquery('russian', :query) q(query)" +
+		" JOIN LATERAL (SELECT d.id, d.date FROM public.dates d WHERE d.id_event = ev.id" + dateFilters +
+		" ORDER BY d.date LIMIT 1) d ON TRUE" +
+		" LEFT JOIN favourite_list fv ON fv.id_event = ev.id AND fv.id_date = d.id AND fv.user_id = :user_id" +
+		" WHERE ev.search_vector @@ q.query" +
+		" AND " + visibleEventCond +
+		" AND ev.id_group IN (SELECT id_group FROM public.news_events_actual_group)" +
+		filters +
+		" ORDER BY rank DESC, d.date, ev.id LIMIT :limit OFFSET :offset"
+
+	rows, err := s.db.NamedQueryContext(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	events := make([]models.EventSearchResult, 0, search.Limit)
 	for rows.Next() {
-		var event models.Event
+		var event models.EventSearchResult
 		if err := rows.StructScan(&event); err != nil {
 			return nil, fmt.Errorf("%s:%w", op, err)
 		}
 		events = append(events, event)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
 
 	return events, nil
 }
+
+// NormalizeEventPrices parses the free text prices of events that have no normalized price yet, e.g. rows
+// created before prices were normalized, or of all events when the parser changed. It returns the number
+// of parsed rows.
+func (s *PgStorage) NormalizeEventPrices(ctx context.Context,
+	parse func(price string) models.EventPrice,
+	all bool) (int, error) {
+	const op = opPrefixPgStorageEvents + "NormalizeEventPrices"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var rows []struct {
+		ID    string `db:"id"`
+		Price string `db:"price"`
+	}
+	if err := tx.SelectContext(ctx, &rows, "select id, price from news_events "+
+		"where price is not null and ($1 or is_free is null) for update", all); err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	for _, row := range rows {
+		p := parse(row.Price)
+		if _, err := tx.ExecContext(ctx, "update news_events set price_min = $1, price_max = $2, "+
+			"price_currency = nullif($3, ''), is_free = $4 where id = $5",
+			p.PriceMin, p.PriceMax, p.PriceCurrency, p.IsFree, row.ID); err != nil {
+			return 0, fmt.Errorf("%s:%w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return len(rows), nil
+}
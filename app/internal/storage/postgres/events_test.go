@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestEventsQuery(t *testing.T) {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	priceMin, priceMax := 100.0, 2000.0
+	fullFilter := models.EventFilter{
+		Categories:     []string{"concerts", "theater"},
+		Sources:        []string{"kudago"},
+		DateFrom:       &from,
+		DateTo:         &to,
+		FreeOnly:       true,
+		PriceMin:       &priceMin,
+		PriceMax:       &priceMax,
+		OnlyFavourites: true,
+		Query:          "джаз",
+	}
+	cursor := &models.EventCursor{
+		Date:      from,
+		Price:     500,
+		CreatedAt: from,
+		DateId:    "5f0c6d1e-8a51-4d43-9d57-4c7c1f0b6f2e",
+	}
+
+	tests := []struct {
+		name   string
+		filter models.EventFilter
+		after  *models.EventCursor
+		limit  int
+	}{
+		{name: "first page", filter: models.EventFilter{}, limit: 50},
+		{name: "next page", filter: models.EventFilter{DateFrom: &from}, after: cursor, limit: 50},
+		{name: "all filters", filter: fullFilter, after: cursor, limit: 10},
+	}
+	for _, sort := range []string{models.SortDate, models.SortPrice, models.SortRecent} {
+		for _, tt := range tests {
+			t.Run(sort+"/"+tt.name, func(t *testing.T) {
+				query, args, err := eventsQuery("user", tt.filter, sort, tt.limit, tt.after)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// every condition is joined with AND, the cursor one included
+				if strings.Contains(query, ")(") || strings.Contains(query, "news_events_actual_group) (") {
+					t.Errorf("conditions are not joined: %s", query)
+				}
+				if tt.after != nil && !strings.Contains(query, " AND "+eventSorts[sort].after) {
+					t.Errorf("cursor condition is missing: %s", query)
+				}
+				if !strings.HasSuffix(query, " LIMIT :limit") || args["limit"] != tt.limit+1 {
+					t.Errorf("page is not limited to %d rows and one more: %s", tt.limit, query)
+				}
+
+				// every named parameter has an argument
+				if _, _, err := sqlx.Named(query, args); err != nil {
+					t.Errorf("binding the arguments: %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestEventsQueryInvalidSort(t *testing.T) {
+	if _, _, err := eventsQuery("user", models.EventFilter{}, "name", 10, nil); !errors.Is(err, ErrInvalidSort) {
+		t.Fatalf("got %v, want %v", err, ErrInvalidSort)
+	}
+}
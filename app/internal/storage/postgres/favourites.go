@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/UdinSemen/moscow-events-backend/internal/metrics"
+	"golang.org/x/net/context"
+)
+
+const opPrefixPgStorageFavourites = "pg_storage.favourites."
+
+func (s *PgStorage) AddFavourite(ctx context.Context, userID, eventID, dateID string) error {
+	const op = opPrefixPgStorageFavourites + "AddFavourite"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	var exists bool
+	if err := s.db.GetContext(ctx, &exists,
+		"select exists(select 1 from dates d where d.id = $1 and d.id_event = $2)", dateID, eventID); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if !exists {
+		return fmt.Errorf("%s:%w", op, ErrNoRows)
+	}
+
+	query := "insert into favourite_list (user_id, user_tg_id, id_event, id_date) " +
+		"select u.id, u.tg_user_id, $2, $3 from users u where u.id = $1 " +
+		"on conflict (user_id, id_event, id_date) do nothing"
+	if _, err := s.db.ExecContext(ctx, query, userID, eventID, dateID); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+func (s *PgStorage) RemoveFavourite(ctx context.Context, userID, eventID, dateID string) error {
+	const op = opPrefixPgStorageFavourites + "RemoveFavourite"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	res, err := s.db.ExecContext(ctx,
+		"delete from favourite_list where user_id = $1 and id_event = $2 and id_date = $3",
+		userID, eventID, dateID)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s:%w", op, ErrNoRows)
+	}
+
+	return nil
+}
+
+func (s *PgStorage) GetFavourites(ctx context.Context, userID string, limit, offset int) ([]models.Event, int, error) {
+	const op = opPrefixPgStorageFavourites + "GetFavourites"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	var total int
+	if err := s.db.GetContext(ctx, &total,
+		"select count(*) from favourite_list fv where fv.user_id = $1", userID); err != nil {
+		return nil, 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	query := "SELECT ev.id, coalesce(ev.label, '') AS label, coalesce(ev.description, '') AS description, " +
+		"d.id AS date_id, d.date, coalesce(ev.price, '') AS price, " + priceCols + ", coalesce(ev.url_buy, '') AS url_buy, " +
+		"coalesce(ev.url_img, '') AS url_img, TRUE AS is_favorite" +
+		" FROM favourite_list fv JOIN news_events ev ON ev.id = fv.id_event JOIN dates d ON d.id = fv.id_date" +
+		" WHERE fv.user_id = $1" +
+		" ORDER BY d.date, fv.created_at LIMIT $2 OFFSET $3"
+
+	events := make([]models.Event, 0, limit)
+	if err := s.db.SelectContext(ctx, &events, query, userID, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return events, total, nil
+}
@@ -0,0 +1,265 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/net/context"
+)
+
+const opPrefixPgStorageMigrate = "pg_storage.migrate."
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var (
+	ErrMigrationChecksum = errors.New("applied migration differs from the embedded one")
+	ErrUnknownMigration  = errors.New("database has a migration unknown to this binary")
+
+	migrationNameRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+	// releasedChecksums are the checksums of earlier texts of migrations that were edited afterwards in
+	// comments only, databases that applied those texts are up to date.
+	releasedChecksums = map[int][]string{
+		3: {"e8a414fbec6ab62df4aa46f984746e18d0257b50c9e615d52a40a6464b0d0010"},
+		7: {"39878e9d115ee63fa7420c247b11a3963e07e488a2d9058fe9016bfa2c0079e0"},
+	}
+)
+
+// Migration is a versioned schema change, Checksum is the sha256 of its up script.
+type Migration struct {
+	Version  int
+	Name     string
+	Checksum string
+	up       string
+	down     string
+}
+
+type appliedMigration struct {
+	Version  int    `db:"version"`
+	Name     string `db:"name"`
+	Checksum string `db:"checksum"`
+}
+
+// Migrations returns the embedded migrations ordered by version.
+func Migrations() ([]Migration, error) {
+	const op = opPrefixPgStorageMigrate + "Migrations"
+
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		parts := migrationNameRegexp.FindStringSubmatch(entry.Name())
+		if parts == nil {
+			return nil, fmt.Errorf("%s:invalid migration file name %s", op, entry.Name())
+		}
+		version, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%w", op, err)
+		}
+		script, err := fs.ReadFile(migrationFiles, path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%w", op, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = m
+		}
+		if parts[3] == "up" {
+			sum := sha256.Sum256(script)
+			m.up, m.Checksum = string(script), hex.EncodeToString(sum[:])
+		} else {
+			m.down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("%s:migration %d has no up or down script", op, m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// LatestMigration is the version the embedded migrations bring the schema to.
+func LatestMigration() (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+	if len(migrations) == 0 {
+		return 0, nil
+	}
+	return migrations[len(migrations)-1].Version, nil
+}
+
+// MigrateUp applies the pending migrations and returns their number. The whole run is one transaction
+// holding a lock on schema_migrations, so concurrently starting instances apply every migration once.
+func (s *PgStorage) MigrateUp(ctx context.Context) (int, error) {
+	const op = opPrefixPgStorageMigrate + "MigrateUp"
+
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	tx, applied, err := s.beginMigration(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := checkMigrations(migrations, applied); err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	var count int
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, m.up); err != nil {
+			return 0, fmt.Errorf("%s:migration %d_%s:%w", op, m.Version, m.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx, "insert into schema_migrations (version, name, checksum) values ($1, $2, $3)",
+			m.Version, m.Name, m.Checksum); err != nil {
+			return 0, fmt.Errorf("%s:%w", op, err)
+		}
+		count++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return count, nil
+}
+
+// MigrateDown reverts the last steps applied migrations and returns the number of reverted ones.
+func (s *PgStorage) MigrateDown(ctx context.Context, steps int) (int, error) {
+	const op = opPrefixPgStorageMigrate + "MigrateDown"
+
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	tx, applied, err := s.beginMigration(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := checkMigrations(migrations, applied); err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	var count int
+	for i := len(migrations) - 1; i >= 0 && count < steps; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, m.down); err != nil {
+			return 0, fmt.Errorf("%s:migration %d_%s:%w", op, m.Version, m.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx, "delete from schema_migrations where version = $1", m.Version); err != nil {
+			return 0, fmt.Errorf("%s:%w", op, err)
+		}
+		count++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return count, nil
+}
+
+// MigrationVersion returns the latest applied migration, zero for a database never migrated.
+func (s *PgStorage) MigrationVersion(ctx context.Context) (int, error) {
+	const op = opPrefixPgStorageMigrate + "MigrationVersion"
+
+	var exists bool
+	if err := s.db.GetContext(ctx, &exists, "select to_regclass('schema_migrations') is not null"); err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+	if !exists {
+		return 0, nil
+	}
+
+	var version int
+	if err := s.db.GetContext(ctx, &version, "select coalesce(max(version), 0) from schema_migrations"); err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+	return version, nil
+}
+
+// beginMigration opens the migration transaction and returns the applied migrations by version.
+func (s *PgStorage) beginMigration(ctx context.Context) (*sqlx.Tx, map[int]appliedMigration, error) {
+	if _, err := s.db.ExecContext(ctx, "create table if not exists schema_migrations "+
+		"(version int primary key, name varchar(1024) not null, checksum varchar(64) not null, "+
+		"applied_at timestamp default now())"); err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the lock conflicts with itself and is held until the end of the transaction
+	if _, err := tx.ExecContext(ctx, "lock table schema_migrations in share row exclusive mode"); err != nil {
+		_ = tx.Rollback()
+		return nil, nil, err
+	}
+
+	var rows []appliedMigration
+	if err := tx.SelectContext(ctx, &rows, "select version, name, checksum from schema_migrations"); err != nil {
+		_ = tx.Rollback()
+		return nil, nil, err
+	}
+	applied := make(map[int]appliedMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return tx, applied, nil
+}
+
+// checkMigrations refuses to run against a database whose applied migrations were edited afterwards or
+// come from a newer binary.
+func checkMigrations(migrations []Migration, applied map[int]appliedMigration) error {
+	known := make(map[int]Migration, len(migrations))
+	for _, m := range migrations {
+		known[m.Version] = m
+	}
+	for version, a := range applied {
+		m, ok := known[version]
+		if !ok {
+			return fmt.Errorf("%w: %d_%s", ErrUnknownMigration, version, a.Name)
+		}
+		if m.Checksum != a.Checksum && !slices.Contains(releasedChecksums[version], a.Checksum) {
+			return fmt.Errorf("%w: %d_%s", ErrMigrationChecksum, version, m.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+
+	// versions go one after another from 1, every migration has both scripts and a checksum
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %d_%s: want version %d", m.Version, m.Name, i+1)
+		}
+		if m.up == "" || m.down == "" || len(m.Checksum) != 64 {
+			t.Errorf("migration %d_%s is incomplete", m.Version, m.Name)
+		}
+	}
+
+	latest, err := LatestMigration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest != migrations[len(migrations)-1].Version {
+		t.Errorf("LatestMigration() = %d, want %d", latest, migrations[len(migrations)-1].Version)
+	}
+}
+
+func TestMigrationNameRegexp(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"0001_init.up.sql", true},
+		{"0007_events_price.down.sql", true},
+		{"0001_init.sql", false},
+		{"init.up.sql", false},
+		{"0001_init.up.sql.bak", false},
+		{"0001-init.up.sql", false},
+	}
+	for _, tt := range tests {
+		if got := migrationNameRegexp.MatchString(tt.name); got != tt.want {
+			t.Errorf("match %q = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckMigrations(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Name: "init", Checksum: "a"},
+		{Version: 2, Name: "events", Checksum: "b"},
+		{Version: 7, Name: "events_price", Checksum: "e"},
+	}
+
+	tests := []struct {
+		name    string
+		applied map[int]appliedMigration
+		wantErr error
+	}{
+		{name: "empty database", applied: map[int]appliedMigration{}},
+		{
+			name:    "partly applied",
+			applied: map[int]appliedMigration{1: {Version: 1, Name: "init", Checksum: "a"}},
+		},
+		{
+			name:    "edited migration",
+			applied: map[int]appliedMigration{1: {Version: 1, Name: "init", Checksum: "c"}},
+			wantErr: ErrMigrationChecksum,
+		},
+		{
+			name:    "released text of a migration edited in comments",
+			applied: map[int]appliedMigration{7: {Version: 7, Name: "events_price", Checksum: releasedChecksums[7][0]}},
+		},
+		{
+			name: "newer database",
+			applied: map[int]appliedMigration{
+				1: {Version: 1, Name: "init", Checksum: "a"},
+				3: {Version: 3, Name: "future", Checksum: "d"},
+			},
+			wantErr: ErrUnknownMigration,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkMigrations(migrations, tt.applied); !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+drop table if exists public.favourite_list;
+drop table if exists sessions;
+drop table if exists users;
+drop table if exists roles;
+drop table if exists public.dates;
+drop table if exists public.news_events_actual_group;
+drop table if exists public.news_events;
@@ -1,3 +1,5 @@
+-- schema of the first release, every statement is idempotent so databases created by the former
+-- database_up.sql are adopted as they are
 create table if not exists public.news_events
 (
     id          uuid primary key default gen_random_uuid(),
@@ -72,6 +74,3 @@ create table if not exists public.favourite_list
     foreign key (id_event) references news_events (id),
     foreign key (id_date) references dates (id)
 );
-
-
-
@@ -0,0 +1,2 @@
+drop table if exists role_permissions;
+drop table if exists permissions;
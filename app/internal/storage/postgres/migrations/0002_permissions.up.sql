@@ -0,0 +1,36 @@
+create table if not exists permissions
+(
+    id uuid default gen_random_uuid() primary key,
+    name varchar(1024) unique,
+    description text,
+    created_at timestamp default now()
+);
+
+create table if not exists role_permissions
+(
+    role varchar(1024) references roles(type) on delete cascade,
+    permission varchar(1024) references permissions(name) on delete cascade,
+    created_at timestamp default now(),
+    primary key (role, permission)
+);
+
+insert into roles (type) values ('user'), ('moderator'), ('admin')
+on conflict (type) do nothing;
+
+insert into permissions (name, description)
+values ('event.read', 'read events'),
+       ('event.write', 'create, update and delete events'),
+       ('user.read', 'read users'),
+       ('user.write', 'create, update and delete users')
+on conflict (name) do nothing;
+
+insert into role_permissions (role, permission)
+values ('user', 'event.read'),
+       ('moderator', 'event.read'),
+       ('moderator', 'event.write'),
+       ('moderator', 'user.read'),
+       ('admin', 'event.read'),
+       ('admin', 'event.write'),
+       ('admin', 'user.read'),
+       ('admin', 'user.write')
+on conflict (role, permission) do nothing;
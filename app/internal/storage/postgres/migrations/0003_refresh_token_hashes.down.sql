@@ -0,0 +1,6 @@
+-- sessions known by the hash only can't be kept, their users sign in again
+drop table if exists auth_audit;
+drop table if exists refresh_token_history;
+delete from sessions where refresh_token is null;
+alter table sessions alter column refresh_token set not null;
+alter table sessions drop column if exists refresh_token_hash;
@@ -0,0 +1,29 @@
+-- refresh tokens are stored as keyed hashes; sessions only match by hash, so the remaining plaintext
+-- tokens can't be refreshed until the "backfill refresh-tokens" command hashes them after upgrading
+alter table sessions add column if not exists refresh_token_hash varchar(64) unique;
+alter table sessions alter column refresh_token drop not null;
+
+create table if not exists refresh_token_history
+(
+    id uuid default gen_random_uuid() primary key,
+    session_id uuid references sessions (id) on delete cascade,
+    user_id uuid references users (id),
+    refresh_token varchar(1024), -- legacy plaintext token, emptied by the hashing backfill
+    refresh_token_hash varchar(64) unique,
+    rotated_at timestamp default now()
+);
+
+alter table refresh_token_history add column if not exists refresh_token_hash varchar(64) unique;
+alter table refresh_token_history alter column refresh_token drop not null;
+
+create table if not exists auth_audit
+(
+    id uuid default gen_random_uuid() primary key,
+    user_id uuid references users (id) on delete set null,
+    session_id uuid,
+    event varchar(1024) not null,
+    ip varchar(1024),
+    finger_print varchar(2048),
+    details text,
+    created_at timestamp default now()
+);
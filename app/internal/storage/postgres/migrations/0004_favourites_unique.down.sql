@@ -0,0 +1 @@
+drop index if exists favourite_list_user_event_date_idx;
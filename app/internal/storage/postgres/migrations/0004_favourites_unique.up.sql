@@ -0,0 +1,5 @@
+delete from public.favourite_list a using public.favourite_list b
+where a.user_id = b.user_id and a.id_event = b.id_event and a.id_date = b.id_date and a.ctid > b.ctid;
+
+create unique index if not exists favourite_list_user_event_date_idx
+    on public.favourite_list (user_id, id_event, id_date);
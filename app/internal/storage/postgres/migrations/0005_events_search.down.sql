@@ -0,0 +1,2 @@
+drop index if exists news_events_search_vector_idx;
+alter table public.news_events drop column if exists search_vector;
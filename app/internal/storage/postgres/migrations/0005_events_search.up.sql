@@ -0,0 +1,8 @@
+-- full text search document, label is weighted above description
+alter table public.news_events
+    add column if not exists search_vector tsvector generated always as (
+        setweight(to_tsvector('russian', coalesce(label, '')), 'A') ||
+        setweight(to_tsvector('russian', coalesce(description, '')), 'B')
+    ) stored;
+
+create index if not exists news_events_search_vector_idx on public.news_events using gin (search_vector);
@@ -0,0 +1,10 @@
+create table if not exists public.categories
+(
+    slug       varchar(1024) primary key, -- value of news_events.category
+    name       varchar(1024) not null,
+    icon       varchar(1024),
+    color      varchar(32),
+    sort_order int default 0,
+    created_at timestamp default now(),
+    updated_at timestamp
+);
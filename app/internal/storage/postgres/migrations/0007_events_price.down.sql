@@ -0,0 +1,6 @@
+drop index if exists news_events_price_min_idx;
+alter table public.news_events
+    drop column if exists price_min,
+    drop column if exists price_max,
+    drop column if exists price_currency,
+    drop column if exists is_free;
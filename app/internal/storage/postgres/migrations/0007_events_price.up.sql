@@ -0,0 +1,9 @@
+-- normalized price, parsed from the free text one; is_free is null until the price is parsed, the
+-- existing rows are parsed by the "backfill prices" command
+alter table public.news_events
+    add column if not exists price_min      numeric(12, 2),
+    add column if not exists price_max      numeric(12, 2),
+    add column if not exists price_currency varchar(3),
+    add column if not exists is_free        boolean;
+
+create index if not exists news_events_price_min_idx on public.news_events (price_min);
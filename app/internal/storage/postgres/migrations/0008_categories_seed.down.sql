@@ -0,0 +1,4 @@
+delete
+from public.categories
+where slug in ('concerts', 'theater', 'exhibitions', 'cinema', 'festivals',
+               'education', 'kids', 'sport', 'tours', 'other');
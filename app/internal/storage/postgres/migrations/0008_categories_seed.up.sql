@@ -0,0 +1,13 @@
+-- the categories the scrapers write, new ones show up named by their slug until added here
+insert into public.categories (slug, name, icon, color, sort_order)
+values ('concerts', 'Концерты', 'music', '#E5484D', 10),
+       ('theater', 'Театр', 'theater', '#8E4EC6', 20),
+       ('exhibitions', 'Выставки', 'palette', '#0090FF', 30),
+       ('cinema', 'Кино', 'film', '#F76B15', 40),
+       ('festivals', 'Фестивали', 'sparkles', '#E93D82', 50),
+       ('education', 'Лекции и мастер-классы', 'book', '#12A594', 60),
+       ('kids', 'Детям', 'balloon', '#FFC53D', 70),
+       ('sport', 'Спорт', 'trophy', '#30A46C', 80),
+       ('tours', 'Экскурсии', 'map', '#3E63DD', 90),
+       ('other', 'Другое', 'dots', '#8B8D98', 100)
+on conflict (slug) do nothing;
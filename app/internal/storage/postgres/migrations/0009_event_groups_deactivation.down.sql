@@ -0,0 +1,3 @@
+drop trigger if exists news_events_actual_group_stale on public.news_events_actual_group;
+drop function if exists public.news_events_track_stale_group();
+drop table if exists public.news_events_stale_group;
@@ -0,0 +1,38 @@
+-- event groups that stopped being actual and since when, the janitor collects them by deactivated_at;
+-- a trigger keeps it up to date for the imports and the scrapers alike
+create table if not exists public.news_events_stale_group
+(
+    id_group       uuid primary key,
+    deactivated_at timestamp not null default now()
+);
+
+-- groups replaced before the table existed, the time of their last import is the best known bound
+insert into public.news_events_stale_group (id_group, deactivated_at)
+select ev.id_group, max(ev.created_at)
+from public.news_events ev
+where ev.id_group is not null
+  and not exists (select 1 from public.news_events_actual_group g where g.id_group = ev.id_group)
+group by ev.id_group
+on conflict (id_group) do nothing;
+
+create or replace function public.news_events_track_stale_group() returns trigger as
+$$
+begin
+    if tg_op <> 'INSERT' and old.id_group is not null then
+        insert into public.news_events_stale_group (id_group)
+        values (old.id_group)
+        on conflict (id_group) do nothing;
+    end if;
+    if tg_op <> 'DELETE' then
+        delete from public.news_events_stale_group where id_group = new.id_group;
+    end if;
+    return null;
+end;
+$$ language plpgsql;
+
+drop trigger if exists news_events_actual_group_stale on public.news_events_actual_group;
+create trigger news_events_actual_group_stale
+    after insert or update or delete
+    on public.news_events_actual_group
+    for each row
+execute function public.news_events_track_stale_group();
@@ -1,16 +1,21 @@
 package storage
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 
 	"github.com/UdinSemen/moscow-events-backend/internal/config"
+	"github.com/XSAM/otelsql"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 )
 
 const (
 	opPrefixPgStorage = "pg_storage."
+	storageName       = "postgres"
 	TypeTgID          = "tg_user_id"
 	TypeUUID          = "uuid_id"
 )
@@ -29,17 +34,28 @@ func InitPgStorage(cfg *config.Config) (*PgStorage, error) {
 
 	dbConf := cfg.Postgres
 
-	connect, err := sqlx.Connect("postgres", fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		dbConf.Host, dbConf.Port, dbConf.User, dbConf.Password, dbConf.DbName, dbConf.SslMode))
+	db, err := otelsql.Open("postgres", fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		dbConf.Host, dbConf.Port, dbConf.User, dbConf.Password, dbConf.DbName, dbConf.SslMode),
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL))
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	connect := sqlx.NewDb(db, "postgres")
+	if err := connect.Ping(); err != nil {
+		_ = connect.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
 	return &PgStorage{
 		db: connect,
 	}, nil
 }
 
-func (s *PgStorage) Ping() error {
-	return s.db.Ping()
+// DB returns the connection pool of the storage.
+func (s *PgStorage) DB() *sql.DB {
+	return s.db.DB
+}
+
+func (s *PgStorage) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
 }
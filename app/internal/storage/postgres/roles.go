@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/metrics"
+
+	"golang.org/x/net/context"
+)
+
+const opPrefixPgStorageRoles = "pg_storage.roles."
+
+func (s *PgStorage) GetRolePermissions(ctx context.Context, role string) ([]string, error) {
+	const op = opPrefixPgStorageRoles + "GetRolePermissions"
+	defer metrics.ObserveStorage(storageName, op)()
+
+	var permissions []string
+	query := "select rp.permission from role_permissions rp where rp.role = $1"
+	if err := s.db.SelectContext(ctx, &permissions, query, role); err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return permissions, nil
+}
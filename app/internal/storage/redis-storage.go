@@ -1,9 +1,27 @@
 package storage
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Redis interface {
 	Ping(ctx context.Context) error
 	CreateRegSession(ctx context.Context, fingerPrint, timeCode string) error
 	GetRegSession(ctx context.Context, timeCode string) (string, error)
+	RevokeSessions(ctx context.Context, ttl time.Duration, sessionIDs ...string) error
+	IsSessionRevoked(ctx context.Context, sessionID string) (bool, error)
+	DeleteRegSession(ctx context.Context, timeCode string) error
+	RegSessionTTL(ctx context.Context, timeCode string) (time.Duration, error)
+	PublishRegSession(ctx context.Context, timeCode string) error
+	ConfirmRegSession(ctx context.Context, timeCode, userTgID string) error
+	SubscribeRegSession(ctx context.Context, timeCode string) (<-chan struct{}, func() error, error)
+	DeleteRegSessionByFingerPrint(ctx context.Context, fingerPrint string) error
+	RegisterFailure(ctx context.Context, key string, window time.Duration) (int64, error)
+	ResetFailures(ctx context.Context, keys ...string) error
+	Lock(ctx context.Context, key string, ttl time.Duration) error
+	LockTTL(ctx context.Context, keys ...string) (time.Duration, error)
+	GetCache(ctx context.Context, key string) ([]byte, error)
+	SetCache(ctx context.Context, key string, val []byte, ttl time.Duration) error
+	DeleteCache(ctx context.Context, prefix string) error
 }
@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	attemptsTable = "sign_in_attempts."
+	lockTable     = "sign_in_locks."
+)
+
+// RegisterFailure counts a failed attempt for the key within the window and returns the count so far.
+// The window starts with the first failure and isn't prolonged by the next ones.
+func (s *Redis) RegisterFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	const op = "storage.redis.RegisterFailure"
+
+	pipe := s.rdb.TxPipeline()
+	incr := pipe.Incr(ctx, attemptsTable+key)
+	pipe.ExpireNX(ctx, attemptsTable+key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return incr.Val(), nil
+}
+
+func (s *Redis) ResetFailures(ctx context.Context, keys ...string) error {
+	const op = "storage.redis.ResetFailures"
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	redisKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		redisKeys = append(redisKeys, attemptsTable+key)
+	}
+	if err := s.rdb.Del(ctx, redisKeys...).Err(); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+func (s *Redis) Lock(ctx context.Context, key string, ttl time.Duration) error {
+	const op = "storage.redis.Lock"
+
+	if err := s.rdb.Set(ctx, lockTable+key, 1, ttl).Err(); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+// LockTTL returns the longest remaining lock of the keys, zero if none of them is locked.
+func (s *Redis) LockTTL(ctx context.Context, keys ...string) (time.Duration, error) {
+	const op = "storage.redis.LockTTL"
+
+	pipe := s.rdb.Pipeline()
+	ttls := make([]interface{ Val() time.Duration }, 0, len(keys))
+	for _, key := range keys {
+		ttls = append(ttls, pipe.PTTL(ctx, lockTable+key))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	var longest time.Duration
+	for _, ttl := range ttls {
+		// missing keys report negative ttl
+		if ttl.Val() > longest {
+			longest = ttl.Val()
+		}
+	}
+	return longest, nil
+}
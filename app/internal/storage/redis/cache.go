@@ -0,0 +1,51 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const cacheTable = "cache."
+
+// GetCache returns the cached value of the key, redis.Nil if there is none.
+func (s *Redis) GetCache(ctx context.Context, key string) ([]byte, error) {
+	const op = "storage.redis.GetCache"
+
+	val, err := s.rdb.Get(ctx, cacheTable+key).Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return val, nil
+}
+
+// DeleteCache drops the cached values whose keys start with prefix.
+func (s *Redis) DeleteCache(ctx context.Context, prefix string) error {
+	const op = "storage.redis.DeleteCache"
+
+	keys := make([]string, 0)
+	iter := s.rdb.Scan(ctx, 0, cacheTable+prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if err := s.rdb.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+func (s *Redis) SetCache(ctx context.Context, key string, val []byte, ttl time.Duration) error {
+	const op = "storage.redis.SetCache"
+
+	if err := s.rdb.Set(ctx, cacheTable+key, val, ttl).Err(); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
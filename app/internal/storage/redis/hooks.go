@@ -0,0 +1,42 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/logger"
+	"github.com/UdinSemen/moscow-events-backend/internal/metrics"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const storageName = "redis"
+
+// metricsHook times every command, pipelines are timed as a whole. Failed commands are logged with
+// the request of the context, a missing key is not a failure.
+type metricsHook struct{}
+
+func (metricsHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (metricsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		defer metrics.ObserveStorage(storageName, cmd.Name())()
+		err := next(ctx, cmd)
+		if err != nil && !errors.Is(err, redis.Nil) {
+			logger.FromContext(ctx).Debug("redis command failed", zap.String("cmd", cmd.Name()), zap.Error(err))
+		}
+		return err
+	}
+}
+
+func (metricsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		defer metrics.ObserveStorage(storageName, "pipeline")()
+		return next(ctx, cmds)
+	}
+}
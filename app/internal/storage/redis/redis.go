@@ -3,21 +3,35 @@ package redis
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/UdinSemen/moscow-events-backend/internal/config"
 	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
 )
 
 const (
-	timeCodeTTL   = "90s" //seconds
+	// TimeCodeTTL is the lifetime of a time code and its reg session
+	TimeCodeTTL   = 90 * time.Second
 	timeCodeTable = "time_codes."
+	revokedTable  = "revoked_sessions."
+	fingerTable   = "reg_fingerprints."
+	regChannel    = "reg_sessions."
+)
+
+var (
+	ErrTimeCodeExists  = errors.New("time code already in use")
+	ErrRegSessionTaken = errors.New("reg session already confirmed by another user")
 )
 
 type Redis struct {
 	rdb *redis.Client
+	db  int
 }
 
 func NewRedisClient(cfg *config.Config) *Redis {
@@ -26,8 +40,12 @@ func NewRedisClient(cfg *config.Config) *Redis {
 		Password: cfg.Redis.Password,
 		DB:       cfg.Redis.DbName,
 	})
+	rdb.AddHook(metricsHook{})
+	if err := redisotel.InstrumentTracing(rdb); err != nil {
+		zap.S().Warnf("redis commands are not traced: %s", err)
+	}
 
-	return &Redis{rdb: rdb}
+	return &Redis{rdb: rdb, db: cfg.Redis.DbName}
 }
 
 func (s *Redis) Ping(ctx context.Context) error {
@@ -41,11 +59,6 @@ func (s *Redis) Ping(ctx context.Context) error {
 func (s *Redis) CreateRegSession(ctx context.Context, fingerPrint, timeCode string) error {
 	const op = "storage.redis.CreateRegSession"
 
-	dur, err := time.ParseDuration(timeCodeTTL)
-	if err != nil {
-		return fmt.Errorf("%s:%w", op, err)
-	}
-
 	jsonRow, err := json.Marshal(models.RegSession{
 		FingerPrint: fingerPrint,
 		IsConfirmed: false,
@@ -54,7 +67,16 @@ func (s *Redis) CreateRegSession(ctx context.Context, fingerPrint, timeCode stri
 	if err != nil {
 		return fmt.Errorf("%s:%w", op, err)
 	}
-	if err := s.rdb.SetEx(ctx, timeCodeTable+timeCode, jsonRow, dur).Err(); err != nil {
+	ok, err := s.rdb.SetNX(ctx, timeCodeTable+timeCode, jsonRow, TimeCodeTTL).Result()
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if !ok {
+		return fmt.Errorf("%s:%w", op, ErrTimeCodeExists)
+	}
+
+	// lets the reg session be found by fingerprint when it has to be invalidated
+	if err := s.rdb.Set(ctx, fingerTable+fingerPrint, timeCode, TimeCodeTTL).Err(); err != nil {
 		return fmt.Errorf("%s:%w", op, err)
 	}
 
@@ -71,6 +93,187 @@ func (s *Redis) GetRegSession(ctx context.Context, timeCode string) (string, err
 	return val, nil
 }
 
+// RevokeSessions puts sessions on the access token deny list. The ttl should cover the access token
+// lifetime, after that the tokens expire on their own.
+func (s *Redis) RevokeSessions(ctx context.Context, ttl time.Duration, sessionIDs ...string) error {
+	const op = "storage.redis.RevokeSessions"
+
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+
+	pipe := s.rdb.Pipeline()
+	for _, sessionID := range sessionIDs {
+		pipe.Set(ctx, revokedTable+sessionID, 1, ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Redis) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	const op = "storage.redis.IsSessionRevoked"
+
+	n, err := s.rdb.Exists(ctx, revokedTable+sessionID).Result()
+	if err != nil {
+		return false, fmt.Errorf("%s:%w", op, err)
+	}
+	return n > 0, nil
+}
+
+func (s *Redis) DeleteRegSession(ctx context.Context, timeCode string) error {
+	const op = "storage.redis.DeleteRegSession"
+
+	if err := s.rdb.Del(ctx, timeCodeTable+timeCode).Err(); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+func (s *Redis) DeleteRegSessionByFingerPrint(ctx context.Context, fingerPrint string) error {
+	const op = "storage.redis.DeleteRegSessionByFingerPrint"
+
+	timeCode, err := s.rdb.GetDel(ctx, fingerTable+fingerPrint).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	if err := s.rdb.Del(ctx, timeCodeTable+timeCode).Err(); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+func (s *Redis) RegSessionTTL(ctx context.Context, timeCode string) (time.Duration, error) {
+	const op = "storage.redis.RegSessionTTL"
+
+	ttl, err := s.rdb.PTTL(ctx, timeCodeTable+timeCode).Result()
+	if err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+	return ttl, nil
+}
+
+// ConfirmRegSession marks the reg session of the time code as confirmed by the telegram user and
+// notifies the sign in waiters. It returns redis.Nil if there is no such reg session and
+// ErrRegSessionTaken if another telegram user has already confirmed it.
+func (s *Redis) ConfirmRegSession(ctx context.Context, timeCode, userTgID string) error {
+	const op = "storage.redis.ConfirmRegSession"
+
+	key := timeCodeTable + timeCode
+	err := s.rdb.Watch(ctx, func(tx *redis.Tx) error {
+		val, err := tx.Get(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+
+		var session models.RegSession
+		if err := json.Unmarshal([]byte(val), &session); err != nil {
+			return err
+		}
+		if session.IsConfirmed && session.UserID != userTgID {
+			return ErrRegSessionTaken
+		}
+		session.IsConfirmed = true
+		session.UserID = userTgID
+
+		jsonRow, err := json.Marshal(session)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.SetArgs(ctx, key, jsonRow, redis.SetArgs{Mode: "XX", KeepTTL: true})
+			pipe.Publish(ctx, regChannel+timeCode, timeCode)
+			return nil
+		})
+		return err
+	}, key)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	return nil
+}
+
+// PublishRegSession tells the sign in waiters of the time code that its reg session changed.
+func (s *Redis) PublishRegSession(ctx context.Context, timeCode string) error {
+	const op = "storage.redis.PublishRegSession"
+
+	if err := s.rdb.Publish(ctx, regChannel+timeCode, timeCode).Err(); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+// SubscribeRegSession notifies about changes of the reg session of the time code. Confirmers may either
+// publish to the reg session channel or just rewrite the key, the latter is seen through keyspace
+// notifications if they are enabled. Notifications are coalesced, the receiver has to re-read the session.
+// The returned func must be called to unsubscribe.
+func (s *Redis) SubscribeRegSession(ctx context.Context, timeCode string) (<-chan struct{}, func() error, error) {
+	const op = "storage.redis.SubscribeRegSession"
+
+	pubSub := s.rdb.Subscribe(ctx,
+		regChannel+timeCode,
+		fmt.Sprintf("__keyspace@%d__:%s%s", s.db, timeCodeTable, timeCode))
+	// wait for the subscription confirmation, so no update after this call is missed
+	if _, err := pubSub.Receive(ctx); err != nil {
+		_ = pubSub.Close()
+		return nil, nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	updates := make(chan struct{}, 1)
+	go func() {
+		for range pubSub.Channel() {
+			select {
+			case updates <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return updates, pubSub.Close, nil
+}
+
+// EnableKeyspaceNotifications turns on keyspace events for string commands, keeping the events
+// that are already configured. Managed Redis may forbid CONFIG, then only published updates are seen.
+func (s *Redis) EnableKeyspaceNotifications(ctx context.Context) error {
+	const op = "storage.redis.EnableKeyspaceNotifications"
+
+	current, err := s.rdb.ConfigGet(ctx, "notify-keyspace-events").Result()
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+
+	events := current["notify-keyspace-events"]
+	hasKeyspace := strings.Contains(events, "K")
+	hasStrings := strings.Contains(events, "$") || strings.Contains(events, "A")
+	if hasKeyspace && hasStrings {
+		return nil
+	}
+	if !hasKeyspace {
+		events += "K"
+	}
+	if !hasStrings {
+		events += "$"
+	}
+
+	if err := s.rdb.ConfigSet(ctx, "notify-keyspace-events", events).Err(); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+// PoolStats returns the connection pool stats of the client.
+func (s *Redis) PoolStats() *redis.PoolStats {
+	return s.rdb.PoolStats()
+}
+
 func (s *Redis) Close() error {
 	return s.rdb.Close()
 }
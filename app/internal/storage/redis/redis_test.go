@@ -0,0 +1,92 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/UdinSemen/moscow-events-backend/internal/config"
+	"github.com/UdinSemen/moscow-events-backend/internal/domain/models"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedis(t *testing.T) *Redis {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	cfg := &config.Config{}
+	cfg.Redis.Host, cfg.Redis.Port = mr.Host(), mr.Port()
+	s := NewRedisClient(cfg)
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestConfirmRegSession(t *testing.T) {
+	s := newTestRedis(t)
+	ctx := context.Background()
+	const timeCode = "123456"
+
+	if err := s.ConfirmRegSession(ctx, timeCode, "100"); !errors.Is(err, redis.Nil) {
+		t.Fatalf("missing session: got %v, want redis.Nil", err)
+	}
+
+	if err := s.CreateRegSession(ctx, "finger-print", timeCode); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ConfirmRegSession(ctx, timeCode, "100"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ConfirmRegSession(ctx, timeCode, "100"); err != nil {
+		t.Fatalf("confirming again by the same user: %v", err)
+	}
+	if err := s.ConfirmRegSession(ctx, timeCode, "200"); !errors.Is(err, ErrRegSessionTaken) {
+		t.Fatalf("another user: got %v, want %v", err, ErrRegSessionTaken)
+	}
+
+	val, err := s.GetRegSession(ctx, timeCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var session models.RegSession
+	if err := json.Unmarshal([]byte(val), &session); err != nil {
+		t.Fatal(err)
+	}
+	if !session.IsConfirmed || session.UserID != "100" || session.FingerPrint != "finger-print" {
+		t.Errorf("got session %+v", session)
+	}
+
+	ttl, err := s.RegSessionTTL(ctx, timeCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl <= 0 || ttl > TimeCodeTTL {
+		t.Errorf("confirming lost the ttl: %v", ttl)
+	}
+}
+
+func TestDeleteCache(t *testing.T) {
+	s := newTestRedis(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"categories.2024-01-01.2024-01-07", "categories.2024-01-08.2024-01-14", "other.key"} {
+		if err := s.SetCache(ctx, key, []byte("[]"), time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.DeleteCache(ctx, "categories."); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetCache(ctx, "categories.2024-01-01.2024-01-07"); !errors.Is(err, redis.Nil) {
+		t.Errorf("categories cache is kept: %v", err)
+	}
+	if _, err := s.GetCache(ctx, "other.key"); err != nil {
+		t.Errorf("unrelated cache is dropped: %v", err)
+	}
+	if err := s.DeleteCache(ctx, "categories."); err != nil {
+		t.Fatalf("deleting nothing: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const (
+	opPrefix       = "telegram."
+	requestTimeout = 10 * time.Second
+)
+
+var ErrEmptyBotToken = errors.New("empty bot token")
+
+type BotAPI interface {
+	SendMessage(ctx context.Context, chatID int64, text string) error
+	SetWebhook(ctx context.Context, url, secretToken string) error
+}
+
+// Client is a minimal Telegram Bot API client. The api url is configurable so that it can be pointed
+// at a local fake Bot API server.
+type Client struct {
+	apiURL     string
+	token      string
+	httpClient *http.Client
+}
+
+type apiResponse struct {
+	Ok          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+func NewClient(apiURL, token string) (*Client, error) {
+	const op = opPrefix + "NewClient"
+	if token == "" {
+		return nil, fmt.Errorf("%s:%w", op, ErrEmptyBotToken)
+	}
+
+	return &Client{
+		apiURL: strings.TrimRight(apiURL, "/"),
+		token:  token,
+		httpClient: &http.Client{
+			Timeout: requestTimeout,
+			// propagates the trace context of the calls to the Bot API
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+	}, nil
+}
+
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string) error {
+	const op = opPrefix + "SendMessage"
+
+	if err := c.call(ctx, "sendMessage", map[string]interface{}{
+		"chat_id": chatID,
+		"text":    text,
+	}); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+func (c *Client) SetWebhook(ctx context.Context, url, secretToken string) error {
+	const op = opPrefix + "SetWebhook"
+
+	if err := c.call(ctx, "setWebhook", map[string]interface{}{
+		"url":             url,
+		"secret_token":    secretToken,
+		"allowed_updates": []string{"message"},
+	}); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+func (c *Client) call(ctx context.Context, method string, params map[string]interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s/bot%s/%s", c.apiURL, c.token, method), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// the url contains the token, don't let it end up in logs
+		return errors.New(strings.ReplaceAll(err.Error(), c.token, "<token>"))
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var out apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("%s: status %d: %w", method, resp.StatusCode, err)
+	}
+	if !out.Ok {
+		return fmt.Errorf("%s: status %d: %s", method, resp.StatusCode, out.Description)
+	}
+
+	return nil
+}
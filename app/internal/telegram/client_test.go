@@ -0,0 +1,122 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testToken = "123:secret"
+
+// fakeBotAPI is a local Bot API server recording the calls of the client.
+type fakeBotAPI struct {
+	*httptest.Server
+	calls []fakeCall
+	fail  string
+}
+
+type fakeCall struct {
+	Method string
+	Params map[string]interface{}
+}
+
+func newFakeBotAPI(t *testing.T) *fakeBotAPI {
+	t.Helper()
+
+	f := &fakeBotAPI{}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, ok := strings.CutPrefix(r.URL.Path, "/bot"+testToken+"/")
+		if !ok || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"ok":false,"description":"Not Found"}`))
+			return
+		}
+
+		var params map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"ok":false,"description":"Bad Request"}`))
+			return
+		}
+		f.calls = append(f.calls, fakeCall{Method: method, Params: params})
+
+		if f.fail != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(apiResponse{Description: f.fail})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(apiResponse{Ok: true})
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func TestNewClientEmptyToken(t *testing.T) {
+	if _, err := NewClient("http://localhost", ""); err == nil {
+		t.Fatal("expected an error for an empty token")
+	}
+}
+
+func TestClientCalls(t *testing.T) {
+	api := newFakeBotAPI(t)
+	client, err := NewClient(api.URL+"/", testToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.SendMessage(context.Background(), 42, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetWebhook(context.Background(), "https://example.com/telegram/webhook", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(api.calls) != 2 {
+		t.Fatalf("got %d calls, want 2", len(api.calls))
+	}
+	send := api.calls[0]
+	if send.Method != "sendMessage" || send.Params["chat_id"] != float64(42) || send.Params["text"] != "hello" {
+		t.Errorf("unexpected sendMessage call %+v", send)
+	}
+	hook := api.calls[1]
+	if hook.Method != "setWebhook" || hook.Params["url"] != "https://example.com/telegram/webhook" ||
+		hook.Params["secret_token"] != "s3cret" {
+		t.Errorf("unexpected setWebhook call %+v", hook)
+	}
+}
+
+func TestClientAPIError(t *testing.T) {
+	api := newFakeBotAPI(t)
+	api.fail = "Bad Request: chat not found"
+	client, err := NewClient(api.URL, testToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.SendMessage(context.Background(), 42, "hello")
+	if err == nil || !strings.Contains(err.Error(), "chat not found") {
+		t.Fatalf("got %v, want the api description", err)
+	}
+}
+
+func TestClientHidesToken(t *testing.T) {
+	api := newFakeBotAPI(t)
+	url := api.URL
+	api.Close()
+
+	client, err := NewClient(url, testToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.SendMessage(context.Background(), 42, "hello")
+	if err == nil {
+		t.Fatal("expected an error from a closed server")
+	}
+	if strings.Contains(err.Error(), testToken) {
+		t.Errorf("error leaks the token: %v", err)
+	}
+}
@@ -2,22 +2,19 @@ package http_rnd
 
 import (
 	"fmt"
-	"math/rand"
-	"time"
+
+	"github.com/UdinSemen/moscow-events-backend/pkg/random"
 )
 
-const rndNum = 2756983592
+const reqIdBytes = 16
 
 func MakeReqId() (string, error) {
 	const op = "pkg.random.makeReqId"
-	b := make([]byte, 16)
-
-	s := rand.NewSource(time.Now().Unix() + rndNum)
-	r := rand.New(s)
 
-	if _, err := r.Read(b); err != nil {
+	reqId, err := random.Hex(reqIdBytes)
+	if err != nil {
 		return "", fmt.Errorf("%s:%w", op, err)
 	}
 
-	return fmt.Sprintf("%x", b), nil
+	return reqId, nil
 }
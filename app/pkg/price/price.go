@@ -0,0 +1,120 @@
+// Package price parses the free text prices of the event sources, e.g. "от 500 ₽", "бесплатно"
+// or "1 000–3 500 руб.".
+package price
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	RUB = "RUB"
+	USD = "USD"
+	EUR = "EUR"
+)
+
+// Price is a normalized price. Min or Max is nil when the text gives no such bound, both are nil when
+// the text has no price at all.
+type Price struct {
+	Min      *float64
+	Max      *float64
+	Currency string
+	IsFree   bool
+}
+
+var (
+	// age ratings, dates and times are not prices, e.g. "6+", "12–14 декабря", "01.12.2024" or "19:00"
+	noiseRegexp = regexp.MustCompile(`\d+\s*\+` +
+		`|\d{1,2}(?:\s*[–—-]\s*\d{1,2})?\s+(?:январ|феврал|март|апрел|ма[йя]|июн|июл|август|сентябр|октябр|ноябр|декабр|янв|фев|мар|апр|авг|сен|окт|ноя|дек)\p{L}*` +
+		`|\b\d{1,2}\.\d{1,2}\.\d{2,4}\b` +
+		`|\b\d{1,2}:\d{2}\b` +
+		`|\b(?:19|20)\d{2}\s*(?:года?|г\.)`)
+
+	// numbers may be grouped by thousands with regular, non-breaking or narrow spaces
+	numberRegexp   = regexp.MustCompile(`\d{1,3}(?:[ \x{00A0}\x{202F}]\d{3})+(?:[.,]\d{1,2})?|\d+(?:[.,]\d{1,2})?`)
+	numberReplacer = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "", ",", ".")
+	upToRegexp     = regexp.MustCompile(`(?:^|[^\p{L}])до\s*\d`)
+	fromRegexp     = regexp.MustCompile(`(?:^|[^\p{L}])от\s*\d`)
+
+	freeMarkers = []string{"бесплат", "свободн", "free"}
+	currencies  = []struct {
+		code    string
+		markers []string
+	}{
+		{RUB, []string{"₽", "руб", "rub", "р."}},
+		{USD, []string{"$", "usd"}},
+		{EUR, []string{"€", "eur"}},
+	}
+)
+
+// Parse reads a price text. Age ratings, dates and times around the price are skipped. Prices without
+// a currency sign are taken in roubles, the currency of all our sources.
+func Parse(raw string) Price {
+	text := strings.ToLower(strings.TrimSpace(raw))
+	if text == "" {
+		return Price{}
+	}
+
+	for _, marker := range freeMarkers {
+		if strings.Contains(text, marker) {
+			return free()
+		}
+	}
+
+	text = noiseRegexp.ReplaceAllString(text, ";")
+	numbers := parseNumbers(text)
+	if len(numbers) == 0 {
+		return Price{}
+	}
+
+	lo, hi := numbers[0], numbers[0]
+	for _, n := range numbers[1:] {
+		lo, hi = min(lo, n), max(hi, n)
+	}
+	if hi == 0 {
+		return free()
+	}
+
+	p := Price{Currency: currency(text)}
+	switch {
+	case len(numbers) > 1:
+		p.Min, p.Max = &lo, &hi
+	case upToRegexp.MatchString(text):
+		p.Max = &hi
+	case fromRegexp.MatchString(text):
+		p.Min = &lo
+	default:
+		p.Min, p.Max = &lo, &hi
+	}
+	return p
+}
+
+func free() Price {
+	var zero float64
+	return Price{Min: &zero, Max: &zero, IsFree: true}
+}
+
+func parseNumbers(text string) []float64 {
+	matches := numberRegexp.FindAllString(text, -1)
+	numbers := make([]float64, 0, len(matches))
+	for _, m := range matches {
+		n, err := strconv.ParseFloat(numberReplacer.Replace(m), 64)
+		if err != nil {
+			continue
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers
+}
+
+func currency(text string) string {
+	for _, c := range currencies {
+		for _, marker := range c.markers {
+			if strings.Contains(text, marker) {
+				return c.code
+			}
+		}
+	}
+	return RUB
+}
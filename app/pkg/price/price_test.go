@@ -0,0 +1,68 @@
+package price
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	num := func(v float64) *float64 { return &v }
+	free := Price{Min: num(0), Max: num(0), IsFree: true}
+
+	tests := []struct {
+		raw  string
+		want Price
+	}{
+		// the formats of our sources
+		{"от 500 ₽", Price{Min: num(500), Currency: RUB}},
+		{"бесплатно", free},
+		{"1 000–3 500 руб.", Price{Min: num(1000), Max: num(3500), Currency: RUB}},
+
+		{"", Price{}},
+		{"цена уточняется", Price{}},
+		{"Вход свободный", free},
+		{"0 ₽", free},
+		{"500", Price{Min: num(500), Max: num(500), Currency: RUB}},
+		{"до 2 000 ₽", Price{Max: num(2000), Currency: RUB}},
+		{"1 200 – 4 000 ₽", Price{Min: num(1200), Max: num(4000), Currency: RUB}},
+		{"1500,50 руб", Price{Min: num(1500.5), Max: num(1500.5), Currency: RUB}},
+		{"$20", Price{Min: num(20), Max: num(20), Currency: USD}},
+		{"от 15 €", Price{Min: num(15), Currency: EUR}},
+
+		// age ratings, dates and times are not prices
+		{"6+ от 700 ₽", Price{Min: num(700), Currency: RUB}},
+		{"18+, 1 500 ₽", Price{Min: num(1500), Max: num(1500), Currency: RUB}},
+		{"0+ бесплатно", free},
+		{"12 декабря, 1500 руб", Price{Min: num(1500), Max: num(1500), Currency: RUB}},
+		{"12–14 дек., от 900 ₽", Price{Min: num(900), Currency: RUB}},
+		{"01.12.2024 до 300 руб", Price{Max: num(300), Currency: RUB}},
+		{"1 мая 2024 г., 19:00, 2 500 ₽", Price{Min: num(2500), Max: num(2500), Currency: RUB}},
+		{"12+", Price{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got := Parse(tt.raw)
+			if !equal(got, tt.want) {
+				t.Errorf("Parse(%q) = %s, want %s", tt.raw, format(got), format(tt.want))
+			}
+		})
+	}
+}
+
+func equal(a, b Price) bool {
+	eq := func(x, y *float64) bool {
+		return (x == nil && y == nil) || (x != nil && y != nil && *x == *y)
+	}
+	return eq(a.Min, b.Min) && eq(a.Max, b.Max) && a.Currency == b.Currency && a.IsFree == b.IsFree
+}
+
+func format(p Price) string {
+	bound := func(v *float64) string {
+		if v == nil {
+			return "nil"
+		}
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	}
+	return "{min=" + bound(p.Min) + " max=" + bound(p.Max) + " currency=" + p.Currency +
+		" free=" + strconv.FormatBool(p.IsFree) + "}"
+}
@@ -0,0 +1,73 @@
+// Package random generates tokens, codes and identifiers from crypto/rand.
+package random
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+	opPrefix = "pkg.random."
+	// the largest multiple of 10 that fits a byte, bytes above it are rejected to keep digits uniform
+	digitByteLimit = 250
+)
+
+// Bytes returns n random bytes.
+func Bytes(n int) ([]byte, error) {
+	const op = opPrefix + "Bytes"
+
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return b, nil
+}
+
+// Hex returns n random bytes encoded as a 2n long hex string.
+func Hex(n int) (string, error) {
+	const op = opPrefix + "Hex"
+
+	b, err := Bytes(n)
+	if err != nil {
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Digits returns a string of n decimal digits, each uniformly distributed over 0-9.
+func Digits(n int) (string, error) {
+	const op = opPrefix + "Digits"
+
+	code := make([]byte, 0, n)
+	buf := make([]byte, n)
+	for len(code) < n {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("%s:%w", op, err)
+		}
+		for _, b := range buf {
+			if b >= digitByteLimit {
+				continue
+			}
+			code = append(code, '0'+b%10)
+			if len(code) == n {
+				break
+			}
+		}
+	}
+	return string(code), nil
+}
+
+// UUID returns a random (version 4) UUID in its canonical string form.
+func UUID() (string, error) {
+	const op = opPrefix + "UUID"
+
+	b, err := Bytes(16)
+	if err != nil {
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,94 @@
+package random
+
+import (
+	"regexp"
+	"testing"
+)
+
+var (
+	digitsRegexp = regexp.MustCompile(`^[0-9]+$`)
+	hexRegexp    = regexp.MustCompile(`^[0-9a-f]+$`)
+	uuidRegexp   = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+)
+
+func TestFormat(t *testing.T) {
+	for _, n := range []int{1, 6, 16, 32} {
+		digits, err := Digits(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(digits) != n || !digitsRegexp.MatchString(digits) {
+			t.Errorf("Digits(%d) = %q", n, digits)
+		}
+
+		h, err := Hex(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(h) != 2*n || !hexRegexp.MatchString(h) {
+			t.Errorf("Hex(%d) = %q", n, h)
+		}
+	}
+
+	id, err := UUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !uuidRegexp.MatchString(id) {
+		t.Errorf("UUID() = %q", id)
+	}
+}
+
+func TestDigitsDistribution(t *testing.T) {
+	const draws = 20000
+	const codeLen = 6
+
+	var counts [10]int
+	for i := 0; i < draws; i++ {
+		code, err := Digits(codeLen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range code {
+			counts[c-'0']++
+		}
+	}
+
+	expected := float64(draws*codeLen) / 10
+	var chi2 float64
+	for _, count := range counts {
+		d := float64(count) - expected
+		chi2 += d * d / expected
+	}
+	// 9 degrees of freedom, the critical value at p = 0.001 is 27.88
+	if chi2 > 27.88 {
+		t.Errorf("digits are not uniform: chi2 = %.2f, counts = %v", chi2, counts)
+	}
+}
+
+func TestUniqueness(t *testing.T) {
+	const draws = 10000
+
+	tests := []struct {
+		name string
+		gen  func() (string, error)
+	}{
+		{"Hex", func() (string, error) { return Hex(16) }},
+		{"UUID", UUID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen := make(map[string]struct{}, draws)
+			for i := 0; i < draws; i++ {
+				v, err := tt.gen()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, ok := seen[v]; ok {
+					t.Fatalf("duplicate %q after %d draws", v, i)
+				}
+				seen[v] = struct{}{}
+			}
+		})
+	}
+}